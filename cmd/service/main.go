@@ -10,10 +10,15 @@ import (
 	"github.com/joho/godotenv"
 	"go.uber.org/zap"
 
+	"github.com/whiteforestz/iino/internal/domain/hwalerter"
 	"github.com/whiteforestz/iino/internal/domain/hwwatcher"
 	"github.com/whiteforestz/iino/internal/domain/persistor"
+	"github.com/whiteforestz/iino/internal/domain/quotaguard"
 	"github.com/whiteforestz/iino/internal/domain/tglistener"
+	"github.com/whiteforestz/iino/internal/domain/trafficledger"
+	"github.com/whiteforestz/iino/internal/domain/wgprovisioner"
 	"github.com/whiteforestz/iino/internal/domain/wgwatcher"
+	"github.com/whiteforestz/iino/internal/pkg/eventbus"
 	"github.com/whiteforestz/iino/internal/pkg/logger"
 	"github.com/whiteforestz/iino/internal/pkg/sig"
 )
@@ -45,24 +50,50 @@ func main() {
 
 	var (
 		httpClient = &http.Client{}
+		eventBus   = eventbus.New()
 	)
 
 	var (
 		persistorCfg  = persistor.MustNewConfig()
 		hwWatcherCfg  = hwwatcher.MustNewConfig()
 		wgWatcherCfg  = wgwatcher.MustNewConfig()
+		wgProvCfg     = wgprovisioner.MustNewConfig()
+		ledgerCfg     = trafficledger.MustNewConfig()
+		quotaCfg      = quotaguard.MustNewConfig()
+		alertCfg      = hwalerter.MustNewConfig()
 		tgListenerCfg = tglistener.MustNewConfig()
 	)
 
 	var (
 		persistorDomain  = persistor.New(persistorCfg)
-		hwWatcherDomain  = hwwatcher.New(hwWatcherCfg)
-		wgWatcherDomain  = wgwatcher.New(wgWatcherCfg, persistorDomain)
+		hwWatcherDomain  = hwwatcher.New(hwWatcherCfg, eventBus)
+		wgWatcherDomain  = wgwatcher.New(wgWatcherCfg, persistorDomain, eventBus)
+		wgProvDomain     = wgprovisioner.New(wgProvCfg, persistorDomain, wgWatcherDomain)
+		ledgerDomain     = trafficledger.New(ledgerCfg, persistorDomain, wgWatcherDomain)
 		tgListenerDomain = tglistener.New(
 			tgListenerCfg,
 			httpClient,
+			eventbus.Subscribe[wgwatcher.PeerStateChanged](eventBus, "tglistener", 64),
+			eventbus.Subscribe[eventbus.CollectorFailed](eventBus, "tglistener", 64),
 			hwWatcherDomain,
 			wgWatcherDomain,
+			ledgerDomain,
+			wgProvDomain,
+		)
+		quotaDomain = quotaguard.New(
+			quotaCfg,
+			persistorDomain,
+			ledgerDomain,
+			wgWatcherDomain,
+			wgProvDomain,
+			tgListenerDomain,
+		)
+		alertDomain = hwalerter.New(
+			alertCfg,
+			persistorDomain,
+			hwWatcherDomain,
+			eventbus.Subscribe[eventbus.SampleTaken](eventBus, "hwalerter", 16),
+			tgListenerDomain,
 		)
 	)
 
@@ -74,9 +105,29 @@ func main() {
 		return
 	}
 
+	if err = ledgerDomain.Prepare(); err != nil {
+		return
+	}
+
+	if err = quotaDomain.Prepare(); err != nil {
+		return
+	}
+
+	if err = wgProvDomain.Prepare(); err != nil {
+		return
+	}
+
+	if err = alertDomain.Prepare(); err != nil {
+		return
+	}
+
 	hwWatcherDomain.Listen(ctx)
 	wgWatcherDomain.Listen(ctx)
+	ledgerDomain.Listen(ctx)
 	tgListenerDomain.Listen(ctx)
+	quotaDomain.Listen(ctx)
+	wgProvDomain.Listen(ctx)
+	alertDomain.Listen(ctx)
 
 	logger.Instance().Info("Started! Press CTRL-C to interrupt...")
 
@@ -84,7 +135,11 @@ func main() {
 
 	cancel()
 	hwWatcherDomain.Wait()
+	ledgerDomain.Wait()
 	tgListenerDomain.Wait()
+	quotaDomain.Wait()
+	wgProvDomain.Wait()
+	alertDomain.Wait()
 
 	if err = persistorDomain.Clean(); err != nil {
 		return
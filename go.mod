@@ -1,6 +1,6 @@
 module github.com/whiteforestz/iino
 
-go 1.18
+go 1.20
 
 require (
 	github.com/joho/godotenv v1.4.0
@@ -0,0 +1,165 @@
+package hwalerter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/whiteforestz/iino/internal/domain/hwwatcher"
+	"github.com/whiteforestz/iino/internal/pkg/humanize"
+	"github.com/whiteforestz/iino/internal/pkg/logger"
+)
+
+const (
+	// coverageTolerance is how much of the window may miss the samples, e.g. right after the start.
+	coverageTolerance = 20 * time.Second
+)
+
+// evaluateRules evaluates every rule, the alerts are flushed once any of them changes.
+func (d *Domain) evaluateRules(ctx context.Context) error {
+	var changed bool
+	for _, r := range d.rules {
+		ruleChanged, err := d.evaluateRule(ctx, r)
+		if err != nil {
+			logger.Instance().Error("can't evaluate rule", zap.String("rule", r.Name), zap.Error(err))
+		}
+
+		changed = changed || ruleChanged
+	}
+
+	if !changed {
+		return nil
+	}
+
+	if err := d.saveSnapshotAlertAccessor(d.snapshotAlertAccessor); err != nil {
+		return fmt.Errorf("can't flush alerts: %w", err)
+	}
+
+	return nil
+}
+
+// evaluateRule checks every metric matched by the rule, every metric is alerted on its own.
+// The state changes only if the admin is notified, so the failed notification is retried on the next sample.
+func (d *Domain) evaluateRule(ctx context.Context, r rule) (bool, error) {
+	stats, err := d.hwWatcher.GetStats(r.For)
+	if err != nil {
+		if errors.Is(err, hwwatcher.ErrEmptyUsage) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("can't get stats: %w", err)
+	}
+
+	// The rule can't tell the metric stays beyond the threshold for the window, until the history covers it.
+	if stats.To.Sub(stats.From)+coverageTolerance < r.For {
+		return false, nil
+	}
+
+	var (
+		changed    bool
+		keyMatched = make(map[string]struct{})
+	)
+	for _, metric := range stats.Metrics {
+		if matched, _ := path.Match(r.Metric, metric.Name); !matched {
+			continue
+		}
+
+		var (
+			key         = r.Name + "/" + metric.Name
+			a, isFiring = d.snapshotAlertAccessor[key]
+		)
+
+		keyMatched[key] = struct{}{}
+
+		switch {
+		case !isFiring && r.IsFiring(metric.Min, metric.Max):
+			text := fmt.Sprintf(
+				"🚨 %s: %s is %s %s for %s, avg %s",
+				r.Name,
+				metric.Name,
+				r.Op,
+				formatMetricValue(metric.Unit, r.Threshold),
+				humanize.Duration(r.For),
+				formatMetricValue(metric.Unit, metric.Avg),
+			)
+			if err = d.notify(ctx, text); err != nil {
+				continue
+			}
+
+			d.snapshotAlertAccessor[key] = snapshotAlert{
+				Rule:        r.Name,
+				Metric:      metric.Name,
+				FiringSince: stats.To,
+			}
+			changed = true
+
+			logger.Instance().Warn("alert is firing", zap.String("rule", r.Name), zap.String("metric", metric.Name))
+		case isFiring && r.IsResolved(metric.Min, metric.Max):
+			text := fmt.Sprintf(
+				"✅ %s is resolved: %s is back to avg %s, fired for %s",
+				r.Name,
+				metric.Name,
+				formatMetricValue(metric.Unit, metric.Avg),
+				humanize.Duration(stats.To.Sub(a.FiringSince)),
+			)
+			if err = d.notify(ctx, text); err != nil {
+				continue
+			}
+
+			delete(d.snapshotAlertAccessor, key)
+			changed = true
+
+			logger.Instance().Info("alert is resolved", zap.String("rule", r.Name), zap.String("metric", metric.Name))
+		}
+	}
+
+	// The metric may be gone for good, e.g. the disk is detached, so its alert would fire forever.
+	for key, a := range d.snapshotAlertAccessor {
+		if _, found := keyMatched[key]; found || a.Rule != r.Name {
+			continue
+		}
+
+		text := fmt.Sprintf(
+			"✅ %s is resolved: %s is gone, fired for %s",
+			r.Name,
+			a.Metric,
+			humanize.Duration(stats.To.Sub(a.FiringSince)),
+		)
+		if err = d.notify(ctx, text); err != nil {
+			continue
+		}
+
+		delete(d.snapshotAlertAccessor, key)
+		changed = true
+
+		logger.Instance().Info("alert is resolved, metric is gone", zap.String("rule", r.Name), zap.String("metric", a.Metric))
+	}
+
+	return changed, nil
+}
+
+func (d *Domain) notify(ctx context.Context, text string) error {
+	err := d.notifier.Notify(ctx, text)
+	if err != nil {
+		logger.Instance().Error("can't notify", zap.Error(err))
+	}
+
+	return err
+}
+
+func formatMetricValue(unit string, value float64) string {
+	switch unit {
+	case hwwatcher.MetricUnitPercent:
+		return fmt.Sprintf("%.0f%%", value)
+	case hwwatcher.MetricUnitBytesPerSecond:
+		return fmt.Sprintf("%s/s", humanize.Bytes(int64(value)))
+	case hwwatcher.MetricUnitCelsius:
+		return fmt.Sprintf("%.1f %s", value, unit)
+	default:
+		return fmt.Sprintf("%.2f", value)
+	}
+}
@@ -0,0 +1,17 @@
+package hwalerter
+
+import (
+	"github.com/kelseyhightower/envconfig"
+)
+
+type Config struct {
+	// RulesPath is the ini file with the rules, one section per rule, the alerting is off if it isn't set.
+	RulesPath string `split_words:"true"`
+}
+
+func MustNewConfig() Config {
+	var cfg Config
+	envconfig.MustProcess("alert", &cfg)
+
+	return cfg
+}
@@ -0,0 +1,29 @@
+package hwalerter
+
+import (
+	"context"
+	"time"
+
+	"github.com/whiteforestz/iino/internal/domain/hwwatcher"
+	"github.com/whiteforestz/iino/internal/pkg/eventbus"
+)
+
+// SampleSubscriber receives the samples of the watchers, the rules are evaluated on every sample of hwwatcher.
+type SampleSubscriber interface {
+	Events() <-chan eventbus.SampleTaken
+	Close()
+}
+
+type PersistorDomain interface {
+	Save(tag string, b []byte) error
+	Load(tag string) ([]byte, error)
+}
+
+type HWWatcherDomain interface {
+	GetHistoryRetention() time.Duration
+	GetStats(window time.Duration) (*hwwatcher.Stats, error)
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, text string) error
+}
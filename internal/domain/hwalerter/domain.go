@@ -0,0 +1,140 @@
+package hwalerter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/whiteforestz/iino/internal/domain/hwwatcher"
+	"github.com/whiteforestz/iino/internal/pkg/eventbus"
+	"github.com/whiteforestz/iino/internal/pkg/infloop"
+	"github.com/whiteforestz/iino/internal/pkg/logger"
+)
+
+const (
+	tickerPeriod = 1 * time.Second
+)
+
+type Domain struct {
+	started   chan struct{}
+	finished  chan struct{}
+	cfg       Config
+	persistor PersistorDomain
+	hwWatcher HWWatcherDomain
+	samples   SampleSubscriber
+	notifier  Notifier
+
+	prepared              bool
+	rules                 []rule
+	snapshotAlertAccessor map[string]snapshotAlert
+}
+
+func New(
+	cfg Config,
+	persistorDomain PersistorDomain,
+	hwWatcherDomain HWWatcherDomain,
+	sampleSubscriber SampleSubscriber,
+	notifier Notifier,
+) *Domain {
+	return &Domain{
+		started:   make(chan struct{}),
+		finished:  make(chan struct{}),
+		cfg:       cfg,
+		persistor: persistorDomain,
+		hwWatcher: hwWatcherDomain,
+		samples:   sampleSubscriber,
+		notifier:  notifier,
+	}
+}
+
+func (d *Domain) Prepare() error {
+	var err error
+
+	if d.cfg.RulesPath != "" {
+		d.rules, err = loadRules(d.cfg.RulesPath, d.hwWatcher.GetHistoryRetention())
+		if err != nil {
+			return fmt.Errorf("can't load rules: %w", err)
+		}
+	}
+
+	d.snapshotAlertAccessor, err = d.loadSnapshotAlertAccessor()
+	if err != nil {
+		return fmt.Errorf("can't load snapshot alert accessor: %w", err)
+	}
+
+	// The rule may be dropped from the file since the alert fired, so nothing would resolve it.
+	ruleAccessor := make(map[string]struct{}, len(d.rules))
+	for _, r := range d.rules {
+		ruleAccessor[r.Name] = struct{}{}
+	}
+
+	for key, a := range d.snapshotAlertAccessor {
+		if _, found := ruleAccessor[a.Rule]; !found {
+			delete(d.snapshotAlertAccessor, key)
+
+			logger.Instance().Info("alert of unknown rule is dropped", zap.String("rule", a.Rule), zap.String("metric", a.Metric))
+		}
+	}
+
+	d.prepared = true
+
+	return nil
+}
+
+func (d *Domain) Listen(ctx context.Context) {
+	d.guard()
+
+	go d.loop(ctx)
+	<-d.started
+}
+
+func (d *Domain) Wait() {
+	<-d.finished
+}
+
+func (d *Domain) loop(ctx context.Context) {
+	ticker := time.NewTicker(tickerPeriod)
+	defer ticker.Stop()
+
+	infloop.InfLoop(ctx, ticker, infloop.Caller{
+		OnStart: func(_ context.Context) {
+			close(d.started)
+		},
+		OnFinish: func(_ context.Context) {
+			d.samples.Close()
+			close(d.finished)
+		},
+		OnTick: func(ctx context.Context) {
+			if !drainSamples(d.samples.Events()) {
+				return
+			}
+
+			if err := d.evaluateRules(ctx); err != nil {
+				logger.Instance().Error("can't evaluate rules", zap.Error(err))
+			}
+		},
+	})
+}
+
+// drainSamples reports whether hwwatcher has taken a sample since the last tick, the stats are the same otherwise.
+func drainSamples(events <-chan eventbus.SampleTaken) bool {
+	var taken bool
+	for {
+		select {
+		case event := <-events:
+			if event.Source == hwwatcher.EventSource {
+				taken = true
+			}
+		default:
+			return taken
+		}
+	}
+}
+
+func (d *Domain) guard() {
+	if !d.prepared {
+		panic("unprepared domain")
+	}
+}
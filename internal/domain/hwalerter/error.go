@@ -0,0 +1,9 @@
+package hwalerter
+
+import (
+	"errors"
+)
+
+var (
+	ErrInvalidRule = errors.New("invalid rule")
+)
@@ -0,0 +1,28 @@
+package hwalerter
+
+import "time"
+
+const (
+	ruleOpAbove = ">"
+	ruleOpBelow = "<"
+)
+
+// rule fires once the metric stays beyond the threshold for the whole window and resolves once it stays
+// beyond the resolve threshold the other way, the gap between the thresholds keeps the alert from flapping.
+type rule struct {
+	Name      string
+	Metric    string // Metric is the name of the hwwatcher metric, it may be a pattern like "cpu*".
+	Op        string
+	Threshold float64
+	Resolve   float64
+	For       time.Duration
+}
+
+// iniRule is the section of the rules file, the name of the section is the name of the rule.
+type iniRule struct {
+	Metric    string `ini:"metric"`
+	Op        string `ini:"op"`
+	Threshold string `ini:"threshold"`
+	Resolve   string `ini:"resolve"`
+	For       string `ini:"for"`
+}
@@ -0,0 +1,123 @@
+package hwalerter
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+const (
+	ruleDefaultFor = 1 * time.Minute
+)
+
+// loadRules loads the rules, the duration of every rule has to fit the history retention,
+// the rule would never be evaluated otherwise.
+func loadRules(p string, retention time.Duration) ([]rule, error) {
+	file, err := ini.Load(p)
+	if err != nil {
+		return nil, fmt.Errorf("can't load: %w", err)
+	}
+
+	var rules []rule
+	for _, section := range file.Sections() {
+		if section.Name() == ini.DefaultSection {
+			continue
+		}
+
+		var raw iniRule
+		if err = section.MapTo(&raw); err != nil {
+			return nil, fmt.Errorf("can't map rule %q: %w", section.Name(), err)
+		}
+
+		r, err := castRule(section.Name(), raw, retention)
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, *r)
+	}
+
+	return rules, nil
+}
+
+func castRule(name string, raw iniRule, retention time.Duration) (*rule, error) {
+	r := rule{
+		Name:   name,
+		Metric: raw.Metric,
+		Op:     raw.Op,
+		For:    ruleDefaultFor,
+	}
+
+	if r.Metric == "" {
+		return nil, fmt.Errorf("%w: %q has no metric", ErrInvalidRule, name)
+	}
+
+	if _, err := path.Match(r.Metric, ""); err != nil {
+		return nil, fmt.Errorf("%w: %q has invalid metric pattern: %s", ErrInvalidRule, name, err)
+	}
+
+	if r.Op != ruleOpAbove && r.Op != ruleOpBelow {
+		return nil, fmt.Errorf("%w: %q has unknown op %q", ErrInvalidRule, name, r.Op)
+	}
+
+	if raw.Threshold == "" {
+		return nil, fmt.Errorf("%w: %q has no threshold", ErrInvalidRule, name)
+	}
+
+	threshold, err := strconv.ParseFloat(raw.Threshold, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q has invalid threshold %q", ErrInvalidRule, name, raw.Threshold)
+	}
+
+	r.Threshold = threshold
+	r.Resolve = threshold
+
+	if raw.For != "" {
+		d, err := time.ParseDuration(raw.For)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("%w: %q has invalid duration %q", ErrInvalidRule, name, raw.For)
+		}
+
+		r.For = d
+	}
+
+	if r.For > retention {
+		return nil, fmt.Errorf("%w: %q lasts longer than the history retention %s", ErrInvalidRule, name, retention)
+	}
+
+	if raw.Resolve != "" {
+		resolve, err := strconv.ParseFloat(raw.Resolve, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q has invalid resolve %q", ErrInvalidRule, name, raw.Resolve)
+		}
+
+		r.Resolve = resolve
+	}
+
+	if (r.Op == ruleOpAbove && r.Resolve > r.Threshold) || (r.Op == ruleOpBelow && r.Resolve < r.Threshold) {
+		return nil, fmt.Errorf("%w: %q resolves beyond the threshold", ErrInvalidRule, name)
+	}
+
+	return &r, nil
+}
+
+// IsFiring reports whether every value of the window is beyond the threshold.
+func (r *rule) IsFiring(min, max float64) bool {
+	if r.Op == ruleOpAbove {
+		return min > r.Threshold
+	}
+
+	return max < r.Threshold
+}
+
+// IsResolved reports whether every value of the window is back beyond the resolve threshold.
+func (r *rule) IsResolved(min, max float64) bool {
+	if r.Op == ruleOpAbove {
+		return max < r.Resolve
+	}
+
+	return min > r.Resolve
+}
@@ -0,0 +1,77 @@
+package hwalerter
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/whiteforestz/iino/internal/domain/persistor"
+)
+
+const (
+	tagAlert = "alert"
+)
+
+// snapshotAlert is the firing alert, it's keyed by the rule and the metric, so the alert firing
+// before the restart isn't notified again and is resolved later.
+type snapshotAlert struct {
+	Rule        string    `json:"rule"`
+	Metric      string    `json:"metric"`
+	FiringSince time.Time `json:"firingSince"`
+}
+
+func (d *Domain) saveSnapshotAlertAccessor(accessor map[string]snapshotAlert) error {
+	b, err := castSnapshotAlertAccessorToBinary(accessor)
+	if err != nil {
+		return fmt.Errorf("can't cast accessor: %w", err)
+	}
+
+	if err = d.persistor.Save(tagAlert, b); err != nil {
+		return fmt.Errorf("can't save: %w", err)
+	}
+
+	return nil
+}
+
+func (d *Domain) loadSnapshotAlertAccessor() (map[string]snapshotAlert, error) {
+	hash, err := d.persistor.Load(tagAlert)
+	if err != nil {
+		if errors.Is(err, persistor.ErrNotExists) {
+			return make(map[string]snapshotAlert), nil
+		}
+
+		return nil, fmt.Errorf("can't load persited data: %w", err)
+	}
+
+	accessor, err := castSnapshotAlertAccessorFromBinary(hash)
+	if err != nil {
+		return nil, fmt.Errorf("can't cast accessor: %w", err)
+	}
+
+	return accessor, nil
+}
+
+func castSnapshotAlertAccessorToBinary(accessor map[string]snapshotAlert) ([]byte, error) {
+	b, err := json.Marshal(&accessor)
+	if err != nil {
+		return nil, fmt.Errorf("can't marshal: %w", err)
+	}
+
+	return []byte(base64.StdEncoding.EncodeToString(b)), nil
+}
+
+func castSnapshotAlertAccessorFromBinary(hash []byte) (map[string]snapshotAlert, error) {
+	b, err := base64.StdEncoding.DecodeString(string(hash))
+	if err != nil {
+		return nil, fmt.Errorf("can't decode base64: %w", err)
+	}
+
+	var accessor map[string]snapshotAlert
+	if err := json.Unmarshal(b, &accessor); err != nil {
+		return nil, fmt.Errorf("can't unmarshal: %w", err)
+	}
+
+	return accessor, nil
+}
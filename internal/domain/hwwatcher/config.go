@@ -1,14 +1,42 @@
 package hwwatcher
 
-import "github.com/kelseyhightower/envconfig"
+import (
+	"fmt"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
 
 type Config struct {
-	CPULoadSourcePath string `split_words:"true"`
+	CPULoadSourcePath  string  `split_words:"true"`
+	CPUSmoothing       string  `split_words:"true" default:"sma"`
+	CPUSmoothingWindow int     `split_words:"true" default:"2"`
+	CPUSmoothingAlpha  float64 `split_words:"true" default:"0.5"`
+
+	MemorySourcePath  string `split_words:"true"`
+	LoadAvgSourcePath string `split_words:"true"`
+	UptimeSourcePath  string `split_words:"true"`
+
+	DiskStatsSourcePath string   `split_words:"true"`
+	DiskDevices         []string `split_words:"true"`
+	DiskMountpoints     []string `split_words:"true"`
+
+	NetDevSourcePath string   `split_words:"true"`
+	NetInterfaces    []string `split_words:"true"`
+
+	SysfsRootPath string `split_words:"true"`
+	ProcRootPath  string `split_words:"true"`
+
+	HistoryRetention time.Duration `split_words:"true" default:"1h"`
 }
 
 func MustNewConfig() Config {
 	var cfg Config
 	envconfig.MustProcess("hw", &cfg)
 
+	if err := validateSmoothing(cfg); err != nil {
+		panic(fmt.Sprintf("invalid cpu smoothing: %s", err))
+	}
+
 	return cfg
 }
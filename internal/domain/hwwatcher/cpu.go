@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
 	"go.uber.org/zap"
@@ -16,86 +17,127 @@ const (
 	prefixCPU = "cpu"
 )
 
-func (d *Domain) updateCPUUsage(lastCPULoad []cpuCoreLoad) ([]cpuCoreLoad, error) {
-	cpuLoad, err := d.getCurrentCPULoad()
+// updateCPUUsage returns the stat records along with the load, they're the input of the system usage.
+func (d *Domain) updateCPUUsage(lastCPULoad []cpuCoreLoad) ([]cpuCoreLoad, map[string]int64, error) {
+	cpuLoad, statRecords, err := d.getCurrentCPULoad()
 	if err != nil {
-		return nil, fmt.Errorf("can't get current cpu load: %w", err)
+		return nil, nil, fmt.Errorf("can't get current cpu load: %w", err)
 	}
 
 	d.mux.Lock()
 	defer d.mux.Unlock()
 
 	if len(lastCPULoad) == 0 {
-		return cpuLoad, nil
+		return cpuLoad, statRecords, nil
 	}
 
-	if len(cpuLoad) != len(lastCPULoad) {
-		return nil, errors.New("inconsistent length")
+	lastCoreLoadAccessor := make(map[string]cpuCoreLoad, len(lastCPULoad))
+	for _, lastCoreLoad := range lastCPULoad {
+		lastCoreLoadAccessor[lastCoreLoad.Slug] = lastCoreLoad
 	}
 
-	coreLoadAccessor := make(map[string]cpuCoreLoad, len(cpuLoad))
+	var (
+		cpuUsage = make([]CPUCoreUsage, 0, len(cpuLoad))
+		added    []string
+	)
 	for _, coreLoad := range cpuLoad {
-		coreLoadAccessor[coreLoad.Slug] = coreLoad
-	}
-
-	cpuUsage := make([]CPUCoreUsage, 0, len(cpuLoad))
-	for idx, lastCoreLoad := range lastCPULoad {
-		coreLoad, found := coreLoadAccessor[lastCoreLoad.Slug]
+		lastCoreLoad, found := lastCoreLoadAccessor[coreLoad.Slug]
 		if !found {
-			return nil, fmt.Errorf("last cpu load %q not found", lastCoreLoad.Slug)
+			// The core has just come online, so it's baselined now and reported since the next tick.
+			added = append(added, coreLoad.Slug)
+			continue
 		}
+		delete(lastCoreLoadAccessor, coreLoad.Slug)
+
+		var (
+			share    = newCPUCoreShare(coreLoad.Sub(lastCoreLoad))
+			smoothed = d.cpuSmoother.Smooth(coreLoad.Slug, share)
+		)
 
-		diffTotal := coreLoad.GetTotal() - lastCoreLoad.GetTotal()
-		diffIdle := coreLoad.GetTotalIdle() - lastCoreLoad.GetTotalIdle()
+		cpuUsage = append(cpuUsage, smoothed.ToUsage(coreLoad.Slug))
+	}
 
-		var lastCoreUsage int64
-		if len(d.usage.CPU) != 0 {
-			lastCoreUsage = d.usage.CPU[idx].Percentage
+	var removed []string
+	for _, lastCoreLoad := range lastCPULoad {
+		if _, found := lastCoreLoadAccessor[lastCoreLoad.Slug]; !found {
+			continue
 		}
 
-		var coreUsage int64
-		if diffTotal != 0 {
-			coreUsage = 100 * (diffTotal - diffIdle) / diffTotal
+		removed = append(removed, lastCoreLoad.Slug)
+		d.cpuSmoother.Forget(lastCoreLoad.Slug)
+	}
+
+	if len(added) != 0 || len(removed) != 0 {
+		d.usage.CPUTopologyChange = &CPUTopologyChange{
+			At:      time.Now(),
+			Added:   added,
+			Removed: removed,
 		}
 
-		cpuUsage = append(cpuUsage, CPUCoreUsage{
-			Slug:       lastCoreLoad.Slug,
-			Percentage: (lastCoreUsage + coreUsage) / 2,
-		})
+		logger.Instance().Warn(
+			"cpu topology changed",
+			zap.Strings("added", added),
+			zap.Strings("removed", removed),
+		)
 	}
 
 	d.usage.CPU = cpuUsage
 
 	logger.Instance().Debug("cpu usage updated", zap.Any("cpuUsage", cpuUsage))
 
-	return cpuLoad, nil
+	return cpuLoad, statRecords, nil
 }
 
-func (d *Domain) getCurrentCPULoad() ([]cpuCoreLoad, error) {
+// getCurrentCPULoad parses the core lines of the stat file along with the system records of it,
+// so the file is read once per tick.
+func (d *Domain) getCurrentCPULoad() ([]cpuCoreLoad, map[string]int64, error) {
 	raw, err := loadMagicFile(d.cfg.CPULoadSourcePath)
 	if err != nil {
-		return nil, fmt.Errorf("can't load magic file: %w", err)
+		return nil, nil, fmt.Errorf("can't load magic file: %w", err)
 	}
 	lines := strings.Split(raw, "\n")
 
-	var cpuLoad []cpuCoreLoad
+	var (
+		cpuLoad     []cpuCoreLoad
+		statRecords = make(map[string]int64, len(statKeys))
+	)
 	for _, line := range lines {
-		if !strings.HasPrefix(line, prefixCPU) {
-			break
+		if strings.HasPrefix(line, prefixCPU) {
+			coreLoad, err := extractCPUCoreLoad(line)
+			if err != nil {
+				return nil, nil, fmt.Errorf("can't extract core load: %w", err)
+			}
+
+			cpuLoad = append(cpuLoad, *coreLoad)
+
+			continue
 		}
 
-		coreLoad, err := extractCPUCoreLoad(line)
+		tokens := strings.FieldsFunc(line, func(r rune) bool {
+			return unicode.IsSpace(r)
+		})
+		if len(tokens) != 2 {
+			continue
+		}
+
+		parsed, err := strconv.ParseInt(tokens[1], 10, 64)
 		if err != nil {
-			return nil, fmt.Errorf("can't extract core load: %w", err)
+			return nil, nil, fmt.Errorf("invalid format of %q: %w", tokens[0], err)
 		}
 
-		cpuLoad = append(cpuLoad, *coreLoad)
+		statRecords[tokens[0]] = parsed
 	}
 	if len(cpuLoad) == 0 {
-		return nil, errors.New("core lines not found")
+		return nil, nil, errors.New("core lines not found")
+	}
+
+	for _, key := range statKeys {
+		if _, found := statRecords[key]; !found {
+			return nil, nil, fmt.Errorf("%q not found", key)
+		}
 	}
 
-	return cpuLoad, nil
+	return cpuLoad, statRecords, nil
 }
 
 func extractCPUCoreLoad(cpuLine string) (*cpuCoreLoad, error) {
@@ -0,0 +1,5 @@
+package hwwatcher
+
+type EventPublisher interface {
+	Publish(event interface{})
+}
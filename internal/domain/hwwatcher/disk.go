@@ -0,0 +1,207 @@
+package hwwatcher
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"unicode"
+
+	"go.uber.org/zap"
+
+	"github.com/whiteforestz/iino/internal/pkg/logger"
+)
+
+const (
+	diskSectorSize = 512
+)
+
+var (
+	diskIgnoredPrefixes = []string{"loop", "ram"}
+)
+
+func (d *Domain) updateDiskSpaceUsage() error {
+	diskSpaceUsage := make([]DiskSpaceUsage, 0, len(d.cfg.DiskMountpoints))
+	for _, mountpoint := range d.cfg.DiskMountpoints {
+		var stat syscall.Statfs_t
+		if err := syscall.Statfs(mountpoint, &stat); err != nil {
+			return fmt.Errorf("can't stat fs at %q: %w", mountpoint, err)
+		}
+
+		var (
+			blockSize = int64(stat.Bsize)
+			total     = int64(stat.Blocks) * blockSize
+		)
+
+		diskSpaceUsage = append(diskSpaceUsage, DiskSpaceUsage{
+			Mountpoint: mountpoint,
+			TotalBytes: total,
+			UsedBytes:  total - int64(stat.Bfree)*blockSize,
+			FreeBytes:  int64(stat.Bavail) * blockSize,
+		})
+	}
+
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	d.usage.DiskSpace = diskSpaceUsage
+
+	logger.Instance().Debug("disk space usage updated", zap.Any("diskSpaceUsage", diskSpaceUsage))
+
+	return nil
+}
+
+func (d *Domain) updateDiskIOUsage(lastDiskIOLoad []diskDeviceIOLoad) ([]diskDeviceIOLoad, error) {
+	diskIOLoad, err := d.getCurrentDiskIOLoad()
+	if err != nil {
+		return nil, fmt.Errorf("can't get current disk io load: %w", err)
+	}
+
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	if len(lastDiskIOLoad) == 0 {
+		return diskIOLoad, nil
+	}
+
+	lastDeviceLoadAccessor := make(map[string]diskDeviceIOLoad, len(lastDiskIOLoad))
+	for _, lastDeviceLoad := range lastDiskIOLoad {
+		lastDeviceLoadAccessor[lastDeviceLoad.Device] = lastDeviceLoad
+	}
+
+	diskIOUsage := make([]DiskIOUsage, 0, len(diskIOLoad))
+	for _, deviceLoad := range diskIOLoad {
+		lastDeviceLoad, found := lastDeviceLoadAccessor[deviceLoad.Device]
+		if !found {
+			continue
+		}
+
+		elapsed := deviceLoad.At.Sub(lastDeviceLoad.At).Seconds()
+		if elapsed <= 0 {
+			continue
+		}
+
+		var (
+			diffRead  = diffCounter(deviceLoad.SectorsRead, lastDeviceLoad.SectorsRead)
+			diffWrite = diffCounter(deviceLoad.SectorsWritten, lastDeviceLoad.SectorsWritten)
+		)
+
+		diskIOUsage = append(diskIOUsage, DiskIOUsage{
+			Device:              deviceLoad.Device,
+			ReadBytesPerSecond:  int64(float64(diffRead*diskSectorSize) / elapsed),
+			WriteBytesPerSecond: int64(float64(diffWrite*diskSectorSize) / elapsed),
+		})
+	}
+
+	d.usage.DiskIO = diskIOUsage
+
+	logger.Instance().Debug("disk io usage updated", zap.Any("diskIOUsage", diskIOUsage))
+
+	return diskIOLoad, nil
+}
+
+func (d *Domain) getCurrentDiskIOLoad() ([]diskDeviceIOLoad, error) {
+	raw, err := loadMagicFile(d.cfg.DiskStatsSourcePath)
+	if err != nil {
+		return nil, fmt.Errorf("can't load magic file: %w", err)
+	}
+	lines := strings.Split(raw, "\n")
+
+	var (
+		now        = time.Now()
+		diskIOLoad []diskDeviceIOLoad
+		devices    []string
+	)
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		deviceLoad, err := extractDiskDeviceIOLoad(line, now)
+		if err != nil {
+			return nil, fmt.Errorf("can't extract device load: %w", err)
+		}
+
+		if !d.shouldWatchDiskDevice(deviceLoad.Device) {
+			continue
+		}
+
+		diskIOLoad = append(diskIOLoad, *deviceLoad)
+		devices = append(devices, deviceLoad.Device)
+	}
+
+	// The configured devices may be missed for a while, e.g. the disk isn't attached yet.
+	if len(d.cfg.DiskDevices) != 0 {
+		d.diskDeviceMatch.Update(d.cfg.DiskDevices, devices)
+
+		return diskIOLoad, nil
+	}
+
+	if len(diskIOLoad) == 0 {
+		return nil, errors.New("device lines not found")
+	}
+
+	return diskIOLoad, nil
+}
+
+func (d *Domain) shouldWatchDiskDevice(device string) bool {
+	if len(d.cfg.DiskDevices) != 0 {
+		for _, watched := range d.cfg.DiskDevices {
+			if device == watched {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for _, prefix := range diskIgnoredPrefixes {
+		if strings.HasPrefix(device, prefix) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func extractDiskDeviceIOLoad(deviceLine string, at time.Time) (*diskDeviceIOLoad, error) {
+	deviceTokens := strings.FieldsFunc(deviceLine, func(r rune) bool {
+		return unicode.IsSpace(r)
+	})
+	if len(deviceTokens) < 14 {
+		return nil, fmt.Errorf("invalid length: %d", len(deviceTokens))
+	}
+
+	var (
+		device       = deviceTokens[2]
+		rawSectorsRd = deviceTokens[5]
+		rawSectorsWr = deviceTokens[9]
+	)
+
+	sectorsRead, err := strconv.ParseInt(rawSectorsRd, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid format of sectors read: %w", err)
+	}
+
+	sectorsWritten, err := strconv.ParseInt(rawSectorsWr, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid format of sectors written: %w", err)
+	}
+
+	return &diskDeviceIOLoad{
+		Device:         device,
+		At:             at,
+		SectorsRead:    sectorsRead,
+		SectorsWritten: sectorsWritten,
+	}, nil
+}
+
+func diffCounter(current, last int64) int64 {
+	if current < last {
+		return 0
+	}
+
+	return current - last
+}
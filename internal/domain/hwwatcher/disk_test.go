@@ -0,0 +1,133 @@
+package hwwatcher
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetCurrentDiskIOLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		devices []string
+		want    map[string][2]int64 // want holds the sectors read and written by the device.
+	}{
+		{
+			name: "loops ignored",
+			want: map[string][2]int64{
+				"vda":   {11438830, 41596224},
+				"vda1":  {11425846, 41596224},
+				"vda14": {3640, 0},
+				"vda15": {4680, 1},
+			},
+		},
+		{
+			name:    "devices filtered",
+			devices: []string{"vda", "loop0"},
+			want: map[string][2]int64{
+				"vda":   {11438830, 41596224},
+				"loop0": {2124, 0},
+			},
+		},
+		{
+			name:    "devices missed",
+			devices: []string{"sda"},
+			want:    map[string][2]int64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(Config{DiskStatsSourcePath: fixtureDirPath + "/diskstats", DiskDevices: tt.devices}, nil)
+
+			diskIOLoad, err := d.getCurrentDiskIOLoad()
+			if err != nil {
+				t.Fatalf("getCurrentDiskIOLoad() error = %v", err)
+			}
+
+			got := make(map[string][2]int64, len(diskIOLoad))
+			for _, deviceLoad := range diskIOLoad {
+				got[deviceLoad.Device] = [2]int64{deviceLoad.SectorsRead, deviceLoad.SectorsWritten}
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("getCurrentDiskIOLoad() = %v, want %v", got, tt.want)
+			}
+
+			for device, want := range tt.want {
+				if got[device] != want {
+					t.Errorf("device %q sectors = %v, want %v", device, got[device], want)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateDiskIOUsage(t *testing.T) {
+	d := New(Config{DiskStatsSourcePath: fixtureDirPath + "/diskstats", DiskDevices: []string{"vda"}}, nil)
+
+	diskIOLoad, err := d.updateDiskIOUsage(nil)
+	if err != nil {
+		t.Fatalf("updateDiskIOUsage() error = %v", err)
+	}
+
+	if len(d.usage.DiskIO) != 0 {
+		t.Fatalf("updateDiskIOUsage() without the last load = %+v, want none", d.usage.DiskIO)
+	}
+
+	// The last load is two seconds older and behind by 4 KiB read and 8 KiB written.
+	lastDiskIOLoad := []diskDeviceIOLoad{{
+		Device:         "vda",
+		At:             diskIOLoad[0].At.Add(-2 * time.Second),
+		SectorsRead:    diskIOLoad[0].SectorsRead - 8,
+		SectorsWritten: diskIOLoad[0].SectorsWritten - 16,
+	}}
+
+	if _, err = d.updateDiskIOUsage(lastDiskIOLoad); err != nil {
+		t.Fatalf("updateDiskIOUsage() error = %v", err)
+	}
+
+	if len(d.usage.DiskIO) != 1 {
+		t.Fatalf("updateDiskIOUsage() = %+v, want a single device", d.usage.DiskIO)
+	}
+
+	// The fixture is read again, so a bit more than two seconds pass.
+	got := d.usage.DiskIO[0]
+	if got.Device != "vda" || got.ReadBytesPerSecond > 2048 || got.ReadBytesPerSecond < 1800 ||
+		got.WriteBytesPerSecond > 4096 || got.WriteBytesPerSecond < 3600 {
+		t.Errorf("updateDiskIOUsage() = %+v, want about 2048 B/s read and 4096 B/s written", got)
+	}
+}
+
+func TestExtractDiskDeviceIOLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		wantErr bool
+	}{
+		{name: "kernel 5.5 and later", line: " 254       0 vda 186542 41208 11438830 96412 1932850 1185221 41596224 2713904 0 1592384 2908968 0 0 0 0 139574 98651"},
+		{name: "kernel 4.18 and earlier", line: " 254       0 vda 186542 41208 11438830 96412 1932850 1185221 41596224 2713904 0 1592384 2908968"},
+		{name: "short", line: " 254       0 vda 186542 41208 11438830", wantErr: true},
+		{name: "malformed sectors", line: " 254       0 vda 186542 41208 many 96412 1932850 1185221 41596224 2713904 0 1592384 2908968", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractDiskDeviceIOLoad(tt.line, time.Now())
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("extractDiskDeviceIOLoad() = %+v, want error", got)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("extractDiskDeviceIOLoad() error = %v", err)
+			}
+
+			if got.Device != "vda" || got.SectorsRead != 11438830 || got.SectorsWritten != 41596224 {
+				t.Errorf("extractDiskDeviceIOLoad() = %+v", got)
+			}
+		})
+	}
+}
@@ -10,32 +10,48 @@ import (
 
 	"go.uber.org/zap"
 
+	"github.com/whiteforestz/iino/internal/pkg/eventbus"
 	"github.com/whiteforestz/iino/internal/pkg/infloop"
 	"github.com/whiteforestz/iino/internal/pkg/logger"
 )
 
 const (
 	tickerPeriod = 1 * time.Second
+
+	// EventSource is the source of the events published by the watcher.
+	EventSource = "hwwatcher"
 )
 
 type Domain struct {
 	started  chan struct{}
 	finished chan struct{}
 	cfg      Config
+	events   EventPublisher
 
-	mux   *sync.RWMutex
-	usage Usage
+	cpuSmoother     cpuSmoother
+	diskDeviceMatch *filterMatch
+	netIfaceMatch   *filterMatch
+	mux             *sync.RWMutex
+	usage           Usage
+	history         *history
+	processes       []ProcessUsage
 }
 
 func New(
 	cfg Config,
+	eventPublisher EventPublisher,
 ) *Domain {
 	return &Domain{
 		started:  make(chan struct{}),
 		finished: make(chan struct{}),
 		cfg:      cfg,
+		events:   eventPublisher,
 
-		mux: &sync.RWMutex{},
+		cpuSmoother:     newCPUSmoother(cfg),
+		diskDeviceMatch: newFilterMatch("disk devices"),
+		netIfaceMatch:   newFilterMatch("net interfaces"),
+		mux:             &sync.RWMutex{},
+		history:         newHistory(int(cfg.HistoryRetention / tickerPeriod)),
 	}
 }
 
@@ -54,21 +70,95 @@ func (d *Domain) GetUsage() (*Usage, error) {
 	d.mux.RLock()
 	defer d.mux.RUnlock()
 
-	if len(d.usage.CPU) == 0 {
+	if d.usage.isEmpty() {
 		return nil, ErrEmptyUsage
 	}
 
 	usage.CPU = append(usage.CPU, d.usage.CPU...)
 
+	if d.usage.CPUTopologyChange != nil {
+		topologyChange := *d.usage.CPUTopologyChange
+		usage.CPUTopologyChange = &topologyChange
+	}
+
+	if d.usage.Memory != nil {
+		memory := *d.usage.Memory
+		usage.Memory = &memory
+	}
+
+	usage.DiskSpace = append(usage.DiskSpace, d.usage.DiskSpace...)
+	usage.DiskIO = append(usage.DiskIO, d.usage.DiskIO...)
+	usage.Network = append(usage.Network, d.usage.Network...)
+
+	if d.usage.System != nil {
+		system := *d.usage.System
+		usage.System = &system
+	}
+
+	usage.Thermal = append(usage.Thermal, d.usage.Thermal...)
+
 	return &usage, nil
 }
 
+// GetHistoryRetention returns the longest window of the stats.
+func (d *Domain) GetHistoryRetention() time.Duration {
+	return d.cfg.HistoryRetention
+}
+
+func (d *Domain) GetStats(window time.Duration) (*Stats, error) {
+	if window <= 0 || window > d.cfg.HistoryRetention {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidWindow, window)
+	}
+
+	d.mux.RLock()
+	defer d.mux.RUnlock()
+
+	now := time.Now()
+
+	samples := d.history.Since(now.Add(-window))
+	if len(samples) == 0 {
+		return nil, ErrEmptyUsage
+	}
+
+	var (
+		names       []string
+		unitByName  = make(map[string]string)
+		valueByName = make(map[string][]float64)
+	)
+	for _, sample := range samples {
+		for _, metric := range sample.Metrics {
+			if _, found := valueByName[metric.Name]; !found {
+				names = append(names, metric.Name)
+				unitByName[metric.Name] = metric.Unit
+			}
+
+			valueByName[metric.Name] = append(valueByName[metric.Name], metric.Value)
+		}
+	}
+
+	stats := Stats{
+		Window:  window,
+		From:    samples[len(samples)-1].At,
+		To:      samples[0].At,
+		Metrics: make([]MetricStats, 0, len(names)),
+	}
+	for _, name := range names {
+		stats.Metrics = append(stats.Metrics, calcMetricStats(name, unitByName[name], valueByName[name]))
+	}
+
+	return &stats, nil
+}
+
 func (d *Domain) loop(ctx context.Context) {
 	ticker := time.NewTicker(tickerPeriod)
 	defer ticker.Stop()
 
 	var (
-		lastCPULoad []cpuCoreLoad
+		lastCPULoad    []cpuCoreLoad
+		lastDiskIOLoad []diskDeviceIOLoad
+		lastNetLoad    []netInterfaceLoad
+		lastSysLoad    *systemLoad
+		lastProcLoad   map[int]procLoad
 	)
 
 	infloop.InfLoop(ctx, ticker, infloop.Caller{
@@ -79,17 +169,77 @@ func (d *Domain) loop(ctx context.Context) {
 			close(d.finished)
 		},
 		OnTick: func(_ context.Context) {
-			cpuLoad, err := d.updateCPUUsage(lastCPULoad)
+			cpuLoad, statRecords, err := d.updateCPUUsage(lastCPULoad)
+			if err != nil {
+				d.reportCollectorFailure("cpu", err)
+			} else {
+				lastCPULoad = cpuLoad
+			}
+
+			if err = d.updateMemoryUsage(); err != nil {
+				d.reportCollectorFailure("memory", err)
+			}
+
+			if err = d.updateDiskSpaceUsage(); err != nil {
+				d.reportCollectorFailure("disk space", err)
+			}
+
+			diskIOLoad, err := d.updateDiskIOUsage(lastDiskIOLoad)
 			if err != nil {
-				logger.Instance().Error("can't update cpu usage", zap.Error(err))
-				return
+				d.reportCollectorFailure("disk io", err)
+			} else {
+				lastDiskIOLoad = diskIOLoad
 			}
 
-			lastCPULoad = cpuLoad
+			netLoad, err := d.updateNetworkUsage(lastNetLoad)
+			if err != nil {
+				d.reportCollectorFailure("network", err)
+			} else {
+				lastNetLoad = netLoad
+			}
+
+			sysLoad, err := d.updateSystemUsage(lastSysLoad, statRecords)
+			if err != nil {
+				d.reportCollectorFailure("system", err)
+			} else {
+				lastSysLoad = sysLoad
+			}
+
+			if err = d.updateThermalUsage(); err != nil {
+				d.reportCollectorFailure("thermal", err)
+			}
+
+			procLoadAccessor, err := d.updateProcessUsage(lastProcLoad)
+			if err != nil {
+				d.reportCollectorFailure("process", err)
+			} else {
+				lastProcLoad = procLoadAccessor
+			}
+
+			now := time.Now()
+
+			d.recordHistory(now)
+
+			d.events.Publish(eventbus.SampleTaken{
+				Source: EventSource,
+				At:     now,
+			})
 		},
 	})
 }
 
+// reportCollectorFailure logs the failure and publishes it, the failing collector doesn't stop the others.
+func (d *Domain) reportCollectorFailure(collector string, err error) {
+	logger.Instance().Error(fmt.Sprintf("can't update %s usage", collector), zap.Error(err))
+
+	d.events.Publish(eventbus.CollectorFailed{
+		Source:    EventSource,
+		Collector: collector,
+		Err:       err,
+		At:        time.Now(),
+	})
+}
+
 func loadMagicFile(path string) (string, error) {
 	f, err := os.Open(path)
 	if err != nil {
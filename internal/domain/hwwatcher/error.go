@@ -5,5 +5,7 @@ import (
 )
 
 var (
-	ErrEmptyUsage = errors.New("empty usage")
+	ErrEmptyUsage    = errors.New("empty usage")
+	ErrInvalidWindow = errors.New("invalid window")
+	ErrInvalidSort   = errors.New("invalid sort")
 )
@@ -0,0 +1,56 @@
+package hwwatcher
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/whiteforestz/iino/internal/pkg/logger"
+)
+
+// filterMatch tracks the configured names missed in the source, so the filter matching nothing leaves
+// the section empty and is reported once it changes instead of failing the collector on every tick.
+// It's owned by the loop.
+type filterMatch struct {
+	kind    string
+	missing string
+}
+
+func newFilterMatch(kind string) *filterMatch {
+	return &filterMatch{
+		kind: kind,
+	}
+}
+
+func (m *filterMatch) Update(configured, found []string) {
+	foundAccessor := make(map[string]struct{}, len(found))
+	for _, name := range found {
+		foundAccessor[name] = struct{}{}
+	}
+
+	var missing []string
+	for _, name := range configured {
+		if _, isFound := foundAccessor[name]; !isFound {
+			missing = append(missing, name)
+		}
+	}
+
+	key := strings.Join(missing, ",")
+	if key == m.missing {
+		return
+	}
+	m.missing = key
+
+	if len(missing) == 0 {
+		logger.Instance().Info(fmt.Sprintf("configured %s are found", m.kind))
+
+		return
+	}
+
+	logger.Instance().Warn(
+		fmt.Sprintf("configured %s not found", m.kind),
+		zap.Strings("missing", missing),
+		zap.Strings("found", found),
+	)
+}
@@ -0,0 +1,181 @@
+package hwwatcher
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	statsPercentile = 0.95
+)
+
+// history is a ring buffer of the latest samples, the oldest sample is overwritten first.
+type history struct {
+	samples []historySample
+	head    int
+	size    int
+}
+
+type historySample struct {
+	At      time.Time
+	Metrics []historyMetric
+}
+
+type historyMetric struct {
+	Name  string
+	Unit  string
+	Value float64
+}
+
+func newHistory(capacity int) *history {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	return &history{
+		samples: make([]historySample, capacity),
+	}
+}
+
+func (h *history) Push(sample historySample) {
+	h.samples[h.head] = sample
+	h.head = (h.head + 1) % len(h.samples)
+
+	if h.size < len(h.samples) {
+		h.size++
+	}
+}
+
+// Since returns samples taken not earlier than from, the newest sample goes first.
+func (h *history) Since(from time.Time) []historySample {
+	var samples []historySample
+	for i := 1; i <= h.size; i++ {
+		sample := h.samples[(h.head-i+len(h.samples))%len(h.samples)]
+		if sample.At.Before(from) {
+			break
+		}
+
+		samples = append(samples, sample)
+	}
+
+	return samples
+}
+
+func (d *Domain) recordHistory(at time.Time) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	metrics := castUsageToHistoryMetrics(&d.usage)
+	if len(metrics) == 0 {
+		return
+	}
+
+	d.history.Push(historySample{
+		At:      at,
+		Metrics: metrics,
+	})
+}
+
+func castUsageToHistoryMetrics(usage *Usage) []historyMetric {
+	var metrics []historyMetric
+
+	for _, core := range usage.CPU {
+		metrics = append(metrics, historyMetric{
+			Name:  core.Slug,
+			Unit:  MetricUnitPercent,
+			Value: float64(core.Percentage),
+		})
+	}
+
+	if usage.Memory != nil && usage.Memory.TotalBytes != 0 {
+		metrics = append(metrics, historyMetric{
+			Name:  "ram",
+			Unit:  MetricUnitPercent,
+			Value: 100 * float64(usage.Memory.TotalBytes-usage.Memory.AvailableBytes) / float64(usage.Memory.TotalBytes),
+		})
+
+		if usage.Memory.SwapTotalBytes != 0 {
+			metrics = append(metrics, historyMetric{
+				Name:  "swap",
+				Unit:  MetricUnitPercent,
+				Value: 100 * float64(usage.Memory.SwapUsedBytes) / float64(usage.Memory.SwapTotalBytes),
+			})
+		}
+	}
+
+	if usage.System != nil {
+		metrics = append(metrics, historyMetric{
+			Name:  "load1",
+			Unit:  MetricUnitNone,
+			Value: usage.System.Load1,
+		})
+	}
+
+	for _, io := range usage.DiskIO {
+		metrics = append(metrics,
+			historyMetric{
+				Name:  io.Device + " read",
+				Unit:  MetricUnitBytesPerSecond,
+				Value: float64(io.ReadBytesPerSecond),
+			},
+			historyMetric{
+				Name:  io.Device + " write",
+				Unit:  MetricUnitBytesPerSecond,
+				Value: float64(io.WriteBytesPerSecond),
+			},
+		)
+	}
+
+	for _, iface := range usage.Network {
+		metrics = append(metrics,
+			historyMetric{
+				Name:  iface.Name + " rx",
+				Unit:  MetricUnitBytesPerSecond,
+				Value: float64(iface.RxBytesPerSecond),
+			},
+			historyMetric{
+				Name:  iface.Name + " tx",
+				Unit:  MetricUnitBytesPerSecond,
+				Value: float64(iface.TxBytesPerSecond),
+			},
+		)
+	}
+
+	for _, zone := range usage.Thermal {
+		metrics = append(metrics, historyMetric{
+			Name:  zone.Label,
+			Unit:  MetricUnitCelsius,
+			Value: zone.Celsius,
+		})
+	}
+
+	return metrics
+}
+
+func calcMetricStats(name, unit string, values []float64) MetricStats {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, value := range sorted {
+		sum += value
+	}
+
+	// p95 is calculated with the nearest-rank method.
+	p95Idx := int(math.Ceil(statsPercentile*float64(len(sorted)))) - 1
+	if p95Idx < 0 {
+		p95Idx = 0
+	}
+
+	return MetricStats{
+		Name:  name,
+		Unit:  unit,
+		Count: len(sorted),
+		Min:   sorted[0],
+		Avg:   sum / float64(len(sorted)),
+		Max:   sorted[len(sorted)-1],
+		P95:   sorted[p95Idx],
+	}
+}
@@ -0,0 +1,120 @@
+package hwwatcher
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"go.uber.org/zap"
+
+	"github.com/whiteforestz/iino/internal/pkg/logger"
+)
+
+const (
+	memInfoKeyTotal     = "MemTotal"
+	memInfoKeyFree      = "MemFree"
+	memInfoKeyAvailable = "MemAvailable"
+	memInfoKeyBuffers   = "Buffers"
+	memInfoKeyCached    = "Cached"
+	memInfoKeySwapTotal = "SwapTotal"
+	memInfoKeySwapFree  = "SwapFree"
+
+	memInfoUnitKiB = "kB"
+)
+
+func (d *Domain) updateMemoryUsage() error {
+	memInfo, err := d.getCurrentMemInfo()
+	if err != nil {
+		return fmt.Errorf("can't get current mem info: %w", err)
+	}
+
+	total, found := memInfo[memInfoKeyTotal]
+	if !found {
+		return fmt.Errorf("%q not found", memInfoKeyTotal)
+	}
+
+	available, found := memInfo[memInfoKeyAvailable]
+	if !found {
+		// MemAvailable is missing on pre-3.14 kernels, so fall back to a rough estimation.
+		available = memInfo[memInfoKeyFree] + memInfo[memInfoKeyBuffers] + memInfo[memInfoKeyCached]
+	}
+
+	memoryUsage := MemoryUsage{
+		TotalBytes:     total,
+		AvailableBytes: available,
+		BuffersBytes:   memInfo[memInfoKeyBuffers],
+		CachedBytes:    memInfo[memInfoKeyCached],
+		SwapTotalBytes: memInfo[memInfoKeySwapTotal],
+		SwapUsedBytes:  memInfo[memInfoKeySwapTotal] - memInfo[memInfoKeySwapFree],
+	}
+
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	d.usage.Memory = &memoryUsage
+
+	logger.Instance().Debug("memory usage updated", zap.Any("memoryUsage", memoryUsage))
+
+	return nil
+}
+
+func (d *Domain) getCurrentMemInfo() (map[string]int64, error) {
+	raw, err := loadMagicFile(d.cfg.MemorySourcePath)
+	if err != nil {
+		return nil, fmt.Errorf("can't load magic file: %w", err)
+	}
+	lines := strings.Split(raw, "\n")
+
+	memInfo := make(map[string]int64, len(lines))
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+
+		key, value, err := extractMemInfoRecord(line)
+		if err != nil {
+			return nil, fmt.Errorf("can't extract mem info record: %w", err)
+		}
+
+		memInfo[key] = value
+	}
+	if len(memInfo) == 0 {
+		return nil, errors.New("mem info lines not found")
+	}
+
+	return memInfo, nil
+}
+
+func extractMemInfoRecord(memInfoLine string) (string, int64, error) {
+	sepIdx := strings.IndexRune(memInfoLine, ':')
+	if sepIdx == -1 {
+		return "", 0, fmt.Errorf("separator not found in %q", memInfoLine)
+	}
+
+	var (
+		key    = memInfoLine[:sepIdx]
+		tokens = strings.FieldsFunc(memInfoLine[sepIdx+1:], func(r rune) bool {
+			return unicode.IsSpace(r)
+		})
+	)
+	if len(tokens) == 0 || len(tokens) > 2 {
+		return "", 0, fmt.Errorf("invalid length of %q: %d", key, len(tokens))
+	}
+
+	value, err := strconv.ParseInt(tokens[0], 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid format of %q: %w", key, err)
+	}
+
+	if len(tokens) == 2 {
+		if tokens[1] != memInfoUnitKiB {
+			return "", 0, fmt.Errorf("unexpected unit of %q: %q", key, tokens[1])
+		}
+
+		value *= 1024
+	}
+
+	return key, value, nil
+}
@@ -0,0 +1,66 @@
+package hwwatcher
+
+import (
+	"testing"
+)
+
+const fixtureDirPath = "../../../etc/fixture/proc"
+
+func TestUpdateMemoryUsage(t *testing.T) {
+	d := New(Config{MemorySourcePath: fixtureDirPath + "/meminfo"}, nil)
+
+	if err := d.updateMemoryUsage(); err != nil {
+		t.Fatalf("updateMemoryUsage() error = %v", err)
+	}
+
+	want := MemoryUsage{
+		TotalBytes:     1004600 * 1024,
+		AvailableBytes: 612344 * 1024,
+		BuffersBytes:   42108 * 1024,
+		CachedBytes:    458920 * 1024,
+		SwapTotalBytes: 524284 * 1024,
+		SwapUsedBytes:  (524284 - 498172) * 1024,
+	}
+	if got := d.usage.Memory; got == nil || *got != want {
+		t.Errorf("updateMemoryUsage() memory = %+v, want %+v", got, want)
+	}
+}
+
+func TestExtractMemInfoRecord(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      string
+		wantKey   string
+		wantValue int64
+		wantErr   bool
+	}{
+		{name: "kibibytes", line: "MemTotal:        1004600 kB", wantKey: "MemTotal", wantValue: 1004600 * 1024},
+		{name: "no unit", line: "HugePages_Total:       0", wantKey: "HugePages_Total", wantValue: 0},
+		{name: "no separator", line: "MemTotal 1004600 kB", wantErr: true},
+		{name: "no value", line: "MemTotal:", wantErr: true},
+		{name: "unexpected unit", line: "MemTotal:        1004600 MB", wantErr: true},
+		{name: "malformed value", line: "MemTotal:        lots kB", wantErr: true},
+		{name: "extra tokens", line: "MemTotal:        1004600 kB kB", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, value, err := extractMemInfoRecord(tt.line)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("extractMemInfoRecord() = %q, %d, want error", key, value)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("extractMemInfoRecord() error = %v", err)
+			}
+
+			if key != tt.wantKey || value != tt.wantValue {
+				t.Errorf("extractMemInfoRecord() = %q, %d, want %q, %d", key, value, tt.wantKey, tt.wantValue)
+			}
+		})
+	}
+}
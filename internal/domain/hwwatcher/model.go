@@ -1,12 +1,224 @@
 package hwwatcher
 
+import "time"
+
 type Usage struct {
-	CPU []CPUCoreUsage
+	CPU               []CPUCoreUsage
+	CPUTopologyChange *CPUTopologyChange
+	Memory            *MemoryUsage
+	DiskSpace         []DiskSpaceUsage
+	DiskIO            []DiskIOUsage
+	Network           []NetworkInterfaceUsage
+	System            *SystemUsage
+	Thermal           []ThermalZoneUsage
+}
+
+func (u *Usage) isEmpty() bool {
+	return len(u.CPU) == 0 &&
+		u.Memory == nil &&
+		len(u.DiskSpace) == 0 &&
+		len(u.DiskIO) == 0 &&
+		len(u.Network) == 0 &&
+		u.System == nil &&
+		len(u.Thermal) == 0
 }
 
 type CPUCoreUsage struct {
 	Slug       string
 	Percentage int64
+	Breakdown  CPUCoreBreakdown
+}
+
+// CPUCoreBreakdown is a share of time spent in each state, in percents.
+// User and Nice already include Guest and GuestNice respectively.
+type CPUCoreBreakdown struct {
+	User      int64
+	Nice      int64
+	System    int64
+	Idle      int64
+	IOWait    int64
+	IRq       int64
+	SoftIRq   int64
+	Steal     int64
+	Guest     int64
+	GuestNice int64
+}
+
+// CPUTopologyChange is the latest change of the online core set.
+type CPUTopologyChange struct {
+	At      time.Time
+	Added   []string
+	Removed []string
+}
+
+type MemoryUsage struct {
+	TotalBytes     int64
+	AvailableBytes int64
+	BuffersBytes   int64
+	CachedBytes    int64
+	SwapTotalBytes int64
+	SwapUsedBytes  int64
+}
+
+type DiskSpaceUsage struct {
+	Mountpoint string
+	TotalBytes int64
+	UsedBytes  int64
+	FreeBytes  int64
+}
+
+type DiskIOUsage struct {
+	Device              string
+	ReadBytesPerSecond  int64
+	WriteBytesPerSecond int64
+}
+
+type NetworkInterfaceUsage struct {
+	Name               string
+	RxBytesPerSecond   int64
+	TxBytesPerSecond   int64
+	RxPacketsPerSecond int64
+	TxPacketsPerSecond int64
+}
+
+type SystemUsage struct {
+	Load1                    float64
+	Load5                    float64
+	Load15                   float64
+	ProcsRunning             int64
+	ProcsBlocked             int64
+	BootedAt                 time.Time
+	Uptime                   time.Duration
+	ContextSwitchesPerSecond int64
+	ForksPerSecond           int64
+}
+
+type ThermalZoneUsage struct {
+	Label   string
+	Celsius float64
+}
+
+// ProcessUsage is the load of the process over the latest tick.
+type ProcessUsage struct {
+	PID           int
+	Name          string
+	CPUPercentage float64 // CPUPercentage is a share of a single core, so it may exceed 100.
+	RSSBytes      int64
+}
+
+const (
+	MetricUnitNone           = ""
+	MetricUnitPercent        = "%"
+	MetricUnitBytesPerSecond = "B/s"
+	MetricUnitCelsius        = "°C"
+)
+
+type Stats struct {
+	Window  time.Duration
+	From    time.Time
+	To      time.Time
+	Metrics []MetricStats
+}
+
+type MetricStats struct {
+	Name  string
+	Unit  string
+	Count int
+	Min   float64
+	Avg   float64
+	Max   float64
+	P95   float64
+}
+
+// cpuCoreShare is a share of time spent in each state between two loads, in percents.
+type cpuCoreShare struct {
+	Busy      float64
+	User      float64
+	Nice      float64
+	System    float64
+	Idle      float64
+	IOWait    float64
+	IRq       float64
+	SoftIRq   float64
+	Steal     float64
+	Guest     float64
+	GuestNice float64
+}
+
+func newCPUCoreShare(diff cpuCoreLoad) cpuCoreShare {
+	total := float64(diff.GetTotal())
+	if total == 0 {
+		return cpuCoreShare{}
+	}
+
+	percentageOf := func(part int64) float64 {
+		return 100 * float64(part) / total
+	}
+
+	return cpuCoreShare{
+		Busy:      percentageOf(diff.GetTotalNonIdle()),
+		User:      percentageOf(diff.User),
+		Nice:      percentageOf(diff.Nice),
+		System:    percentageOf(diff.System),
+		Idle:      percentageOf(diff.Idle),
+		IOWait:    percentageOf(diff.IOWait),
+		IRq:       percentageOf(diff.IRq),
+		SoftIRq:   percentageOf(diff.SoftIRq),
+		Steal:     percentageOf(diff.Steal),
+		Guest:     percentageOf(diff.Guest),
+		GuestNice: percentageOf(diff.GuestNice),
+	}
+}
+
+func (cpucs cpuCoreShare) Add(other cpuCoreShare) cpuCoreShare {
+	return cpuCoreShare{
+		Busy:      cpucs.Busy + other.Busy,
+		User:      cpucs.User + other.User,
+		Nice:      cpucs.Nice + other.Nice,
+		System:    cpucs.System + other.System,
+		Idle:      cpucs.Idle + other.Idle,
+		IOWait:    cpucs.IOWait + other.IOWait,
+		IRq:       cpucs.IRq + other.IRq,
+		SoftIRq:   cpucs.SoftIRq + other.SoftIRq,
+		Steal:     cpucs.Steal + other.Steal,
+		Guest:     cpucs.Guest + other.Guest,
+		GuestNice: cpucs.GuestNice + other.GuestNice,
+	}
+}
+
+func (cpucs cpuCoreShare) Scale(k float64) cpuCoreShare {
+	return cpuCoreShare{
+		Busy:      cpucs.Busy * k,
+		User:      cpucs.User * k,
+		Nice:      cpucs.Nice * k,
+		System:    cpucs.System * k,
+		Idle:      cpucs.Idle * k,
+		IOWait:    cpucs.IOWait * k,
+		IRq:       cpucs.IRq * k,
+		SoftIRq:   cpucs.SoftIRq * k,
+		Steal:     cpucs.Steal * k,
+		Guest:     cpucs.Guest * k,
+		GuestNice: cpucs.GuestNice * k,
+	}
+}
+
+func (cpucs cpuCoreShare) ToUsage(slug string) CPUCoreUsage {
+	return CPUCoreUsage{
+		Slug:       slug,
+		Percentage: roundPercentage(cpucs.Busy),
+		Breakdown: CPUCoreBreakdown{
+			User:      roundPercentage(cpucs.User),
+			Nice:      roundPercentage(cpucs.Nice),
+			System:    roundPercentage(cpucs.System),
+			Idle:      roundPercentage(cpucs.Idle),
+			IOWait:    roundPercentage(cpucs.IOWait),
+			IRq:       roundPercentage(cpucs.IRq),
+			SoftIRq:   roundPercentage(cpucs.SoftIRq),
+			Steal:     roundPercentage(cpucs.Steal),
+			Guest:     roundPercentage(cpucs.Guest),
+			GuestNice: roundPercentage(cpucs.GuestNice),
+		},
+	}
 }
 
 type cpuCoreLoad struct {
@@ -34,3 +246,61 @@ func (cpucl *cpuCoreLoad) GetTotalNonIdle() int64 {
 func (cpucl *cpuCoreLoad) GetTotal() int64 {
 	return cpucl.GetTotalIdle() + cpucl.GetTotalNonIdle()
 }
+
+func (cpucl *cpuCoreLoad) Sub(last cpuCoreLoad) cpuCoreLoad {
+	return cpuCoreLoad{
+		Slug:      cpucl.Slug,
+		User:      cpucl.User - last.User,
+		Nice:      cpucl.Nice - last.Nice,
+		System:    cpucl.System - last.System,
+		Idle:      cpucl.Idle - last.Idle,
+		IOWait:    cpucl.IOWait - last.IOWait,
+		IRq:       cpucl.IRq - last.IRq,
+		SoftIRq:   cpucl.SoftIRq - last.SoftIRq,
+		Steal:     cpucl.Steal - last.Steal,
+		Guest:     cpucl.Guest - last.Guest,
+		GuestNice: cpucl.GuestNice - last.GuestNice,
+	}
+}
+
+type diskDeviceIOLoad struct {
+	Device         string
+	At             time.Time
+	SectorsRead    int64 // SectorsRead is total sectors read, a sector is always 512 bytes.
+	SectorsWritten int64 // SectorsWritten is total sectors written, a sector is always 512 bytes.
+}
+
+type netInterfaceLoad struct {
+	Name      string
+	At        time.Time
+	RxBytes   int64 // RxBytes is total bytes received.
+	RxPackets int64 // RxPackets is total packets received.
+	TxBytes   int64 // TxBytes is total bytes transmitted.
+	TxPackets int64 // TxPackets is total packets transmitted.
+}
+
+type systemLoad struct {
+	At              time.Time
+	Load1           float64       // Load1 is load average over the last minute.
+	Load5           float64       // Load5 is load average over the last 5 minutes.
+	Load15          float64       // Load15 is load average over the last 15 minutes.
+	Uptime          time.Duration // Uptime is time elapsed since the system boot.
+	BootTimeUnix    int64         // BootTimeUnix is time of the system boot.
+	ContextSwitches int64         // ContextSwitches is total context switches across all CPUs.
+	Processes       int64         // Processes is total forks since the system boot.
+	ProcsRunning    int64         // ProcsRunning is processes in runnable state.
+	ProcsBlocked    int64         // ProcsBlocked is processes blocked waiting for I/O.
+}
+
+type procLoad struct {
+	Name       string
+	At         time.Time
+	UserTime   int64 // UserTime is time spent in user mode, in clock ticks.
+	SystemTime int64 // SystemTime is time spent in kernel mode, in clock ticks.
+	StartTime  int64 // StartTime is time the process started after the system boot, in clock ticks.
+	RSSBytes   int64 // RSSBytes is resident set size.
+}
+
+func (pl *procLoad) GetTotalTime() int64 {
+	return pl.UserTime + pl.SystemTime
+}
@@ -0,0 +1,165 @@
+package hwwatcher
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.uber.org/zap"
+
+	"github.com/whiteforestz/iino/internal/pkg/logger"
+)
+
+const (
+	netDevHeaderLength = 2
+)
+
+func (d *Domain) updateNetworkUsage(lastNetworkLoad []netInterfaceLoad) ([]netInterfaceLoad, error) {
+	networkLoad, err := d.getCurrentNetworkLoad()
+	if err != nil {
+		return nil, fmt.Errorf("can't get current network load: %w", err)
+	}
+
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	if len(lastNetworkLoad) == 0 {
+		return networkLoad, nil
+	}
+
+	lastInterfaceLoadAccessor := make(map[string]netInterfaceLoad, len(lastNetworkLoad))
+	for _, lastInterfaceLoad := range lastNetworkLoad {
+		lastInterfaceLoadAccessor[lastInterfaceLoad.Name] = lastInterfaceLoad
+	}
+
+	networkUsage := make([]NetworkInterfaceUsage, 0, len(networkLoad))
+	for _, interfaceLoad := range networkLoad {
+		lastInterfaceLoad, found := lastInterfaceLoadAccessor[interfaceLoad.Name]
+		if !found {
+			continue
+		}
+
+		elapsed := interfaceLoad.At.Sub(lastInterfaceLoad.At).Seconds()
+		if elapsed <= 0 {
+			continue
+		}
+
+		perSecond := func(current, last int64) int64 {
+			return int64(float64(diffCounter(current, last)) / elapsed)
+		}
+
+		networkUsage = append(networkUsage, NetworkInterfaceUsage{
+			Name:               interfaceLoad.Name,
+			RxBytesPerSecond:   perSecond(interfaceLoad.RxBytes, lastInterfaceLoad.RxBytes),
+			TxBytesPerSecond:   perSecond(interfaceLoad.TxBytes, lastInterfaceLoad.TxBytes),
+			RxPacketsPerSecond: perSecond(interfaceLoad.RxPackets, lastInterfaceLoad.RxPackets),
+			TxPacketsPerSecond: perSecond(interfaceLoad.TxPackets, lastInterfaceLoad.TxPackets),
+		})
+	}
+
+	d.usage.Network = networkUsage
+
+	logger.Instance().Debug("network usage updated", zap.Any("networkUsage", networkUsage))
+
+	return networkLoad, nil
+}
+
+func (d *Domain) getCurrentNetworkLoad() ([]netInterfaceLoad, error) {
+	raw, err := loadMagicFile(d.cfg.NetDevSourcePath)
+	if err != nil {
+		return nil, fmt.Errorf("can't load magic file: %w", err)
+	}
+	lines := strings.Split(raw, "\n")
+	if len(lines) < netDevHeaderLength {
+		return nil, fmt.Errorf("unexpected lines count: %d", len(lines))
+	}
+
+	var (
+		now         = time.Now()
+		networkLoad []netInterfaceLoad
+		names       []string
+	)
+	for _, line := range lines[netDevHeaderLength:] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		interfaceLoad, err := extractNetInterfaceLoad(line, now)
+		if err != nil {
+			return nil, fmt.Errorf("can't extract interface load: %w", err)
+		}
+
+		if !d.shouldWatchNetInterface(interfaceLoad.Name) {
+			continue
+		}
+
+		networkLoad = append(networkLoad, *interfaceLoad)
+		names = append(names, interfaceLoad.Name)
+	}
+
+	// The configured interfaces may be missed for a while, e.g. the tunnel isn't up yet.
+	if len(d.cfg.NetInterfaces) != 0 {
+		d.netIfaceMatch.Update(d.cfg.NetInterfaces, names)
+
+		return networkLoad, nil
+	}
+
+	if len(networkLoad) == 0 {
+		return nil, errors.New("interface lines not found")
+	}
+
+	return networkLoad, nil
+}
+
+func (d *Domain) shouldWatchNetInterface(name string) bool {
+	if len(d.cfg.NetInterfaces) == 0 {
+		return true
+	}
+
+	for _, watched := range d.cfg.NetInterfaces {
+		if name == watched {
+			return true
+		}
+	}
+
+	return false
+}
+
+func extractNetInterfaceLoad(interfaceLine string, at time.Time) (*netInterfaceLoad, error) {
+	sepIdx := strings.IndexRune(interfaceLine, ':')
+	if sepIdx == -1 {
+		return nil, fmt.Errorf("separator not found in %q", interfaceLine)
+	}
+
+	var (
+		name   = strings.TrimSpace(interfaceLine[:sepIdx])
+		tokens = strings.FieldsFunc(interfaceLine[sepIdx+1:], func(r rune) bool {
+			return unicode.IsSpace(r)
+		})
+	)
+	if len(tokens) != 16 {
+		return nil, fmt.Errorf("invalid length of %q: %d", name, len(tokens))
+	}
+
+	recs := make([]int64, 0, len(tokens))
+	for idx, rec := range tokens {
+		parsed, err := strconv.ParseInt(rec, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid format of %q at %d: %w", name, idx, err)
+		}
+
+		recs = append(recs, parsed)
+	}
+
+	return &netInterfaceLoad{
+		Name:      name,
+		At:        at,
+		RxBytes:   recs[0],
+		RxPackets: recs[1],
+		TxBytes:   recs[8],
+		TxPackets: recs[9],
+	}, nil
+}
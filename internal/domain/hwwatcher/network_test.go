@@ -0,0 +1,128 @@
+package hwwatcher
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetCurrentNetworkLoad(t *testing.T) {
+	tests := []struct {
+		name       string
+		interfaces []string
+		want       []netInterfaceLoad
+	}{
+		{
+			name: "all interfaces",
+			want: []netInterfaceLoad{
+				{Name: "lo", RxBytes: 4325310, RxPackets: 38121, TxBytes: 4325310, TxPackets: 38121},
+				{Name: "eth0", RxBytes: 98234917322, RxPackets: 81234511, TxBytes: 104355127736, TxPackets: 79821234},
+				{Name: "wg0", RxBytes: 3989644552, RxPackets: 6212345, TxBytes: 3411238897, TxPackets: 5598123},
+			},
+		},
+		{
+			name:       "interfaces filtered",
+			interfaces: []string{"wg0"},
+			want: []netInterfaceLoad{
+				{Name: "wg0", RxBytes: 3989644552, RxPackets: 6212345, TxBytes: 3411238897, TxPackets: 5598123},
+			},
+		},
+		{
+			name:       "interfaces missed",
+			interfaces: []string{"wg1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(Config{NetDevSourcePath: fixtureDirPath + "/net/dev", NetInterfaces: tt.interfaces}, nil)
+
+			got, err := d.getCurrentNetworkLoad()
+			if err != nil {
+				t.Fatalf("getCurrentNetworkLoad() error = %v", err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("getCurrentNetworkLoad() = %+v, want %+v", got, tt.want)
+			}
+
+			for i := range got {
+				got[i].At = time.Time{}
+
+				if got[i] != tt.want[i] {
+					t.Errorf("getCurrentNetworkLoad()[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateNetworkUsage(t *testing.T) {
+	d := New(Config{NetDevSourcePath: fixtureDirPath + "/net/dev", NetInterfaces: []string{"wg0"}}, nil)
+
+	networkLoad, err := d.updateNetworkUsage(nil)
+	if err != nil {
+		t.Fatalf("updateNetworkUsage() error = %v", err)
+	}
+
+	// The last load is two seconds older, and its counters are ahead, as if they were reset.
+	lastNetworkLoad := []netInterfaceLoad{{
+		Name:      "wg0",
+		At:        networkLoad[0].At.Add(-2 * time.Second),
+		RxBytes:   networkLoad[0].RxBytes - 4096,
+		RxPackets: networkLoad[0].RxPackets - 400,
+		TxBytes:   networkLoad[0].TxBytes + 1,
+		TxPackets: networkLoad[0].TxPackets + 1,
+	}}
+
+	if _, err = d.updateNetworkUsage(lastNetworkLoad); err != nil {
+		t.Fatalf("updateNetworkUsage() error = %v", err)
+	}
+
+	if len(d.usage.Network) != 1 {
+		t.Fatalf("updateNetworkUsage() = %+v, want a single interface", d.usage.Network)
+	}
+
+	// The fixture is read again, so a bit more than two seconds pass.
+	got := d.usage.Network[0]
+	if got.Name != "wg0" || got.RxBytesPerSecond > 2048 || got.RxBytesPerSecond < 1800 ||
+		got.RxPacketsPerSecond > 200 || got.RxPacketsPerSecond < 180 ||
+		got.TxBytesPerSecond != 0 || got.TxPacketsPerSecond != 0 {
+		t.Errorf("updateNetworkUsage() = %+v, want about 2048 B/s and 200 packets/s received only", got)
+	}
+}
+
+func TestExtractNetInterfaceLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		wantErr bool
+	}{
+		{name: "spaced", line: "   wg0: 3989644552 6212345    0    0    0     0          0         0 3411238897 5598123    0    7    0     0       0          0"},
+		{name: "glued", line: "wg0:3989644552 6212345 0 0 0 0 0 0 3411238897 5598123 0 7 0 0 0 0"},
+		{name: "no separator", line: "   wg0 3989644552 6212345    0    0    0     0          0         0 3411238897 5598123    0    7    0     0       0          0", wantErr: true},
+		{name: "short", line: "   wg0: 3989644552 6212345", wantErr: true},
+		{name: "malformed counter", line: "   wg0: 3989644552 6212345    0    0    0     0          0         0 3411238897 many    0    7    0     0       0          0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractNetInterfaceLoad(tt.line, time.Time{})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("extractNetInterfaceLoad() = %+v, want error", got)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("extractNetInterfaceLoad() error = %v", err)
+			}
+
+			want := netInterfaceLoad{Name: "wg0", RxBytes: 3989644552, RxPackets: 6212345, TxBytes: 3411238897, TxPackets: 5598123}
+			if *got != want {
+				t.Errorf("extractNetInterfaceLoad() = %+v, want %+v", *got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package hwwatcher
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"unicode"
+
+	"go.uber.org/zap"
+
+	"github.com/whiteforestz/iino/internal/pkg/logger"
+)
+
+const (
+	// userHZ is the unit of the process times, it is assumed to be 100, which holds for x86 and arm,
+	// but not for every architecture, e.g. alpha uses 1024. Reading sysconf(_SC_CLK_TCK) needs cgo.
+	userHZ = 100
+
+	procFileStat   = "stat"
+	procFileStatus = "status"
+	procStatusRSS  = "VmRSS"
+
+	ProcessSortByCPU    = "cpu"
+	ProcessSortByMemory = "mem"
+)
+
+func (d *Domain) GetTopProcesses(sortBy string, limit int) ([]ProcessUsage, error) {
+	var less func(a, b ProcessUsage) bool
+	switch sortBy {
+	case ProcessSortByCPU:
+		less = func(a, b ProcessUsage) bool {
+			return a.CPUPercentage > b.CPUPercentage
+		}
+	case ProcessSortByMemory:
+		less = func(a, b ProcessUsage) bool {
+			return a.RSSBytes > b.RSSBytes
+		}
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidSort, sortBy)
+	}
+
+	d.mux.RLock()
+	defer d.mux.RUnlock()
+
+	if len(d.processes) == 0 {
+		return nil, ErrEmptyUsage
+	}
+
+	processes := make([]ProcessUsage, len(d.processes))
+	copy(processes, d.processes)
+
+	sort.SliceStable(processes, func(i, j int) bool {
+		return less(processes[i], processes[j])
+	})
+
+	if limit > 0 && len(processes) > limit {
+		processes = processes[:limit]
+	}
+
+	return processes, nil
+}
+
+func (d *Domain) updateProcessUsage(lastProcLoad map[int]procLoad) (map[int]procLoad, error) {
+	procLoadAccessor, err := d.getCurrentProcLoad()
+	if err != nil {
+		return nil, fmt.Errorf("can't get current proc load: %w", err)
+	}
+
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	if len(lastProcLoad) == 0 {
+		return procLoadAccessor, nil
+	}
+
+	processes := make([]ProcessUsage, 0, len(procLoadAccessor))
+	for pid, load := range procLoadAccessor {
+		process := ProcessUsage{
+			PID:      pid,
+			Name:     load.Name,
+			RSSBytes: load.RSSBytes,
+		}
+
+		// The pid may be reused by another process, so the start time has to match too.
+		lastLoad, found := lastProcLoad[pid]
+		if found && lastLoad.StartTime == load.StartTime {
+			elapsed := load.At.Sub(lastLoad.At).Seconds()
+			if elapsed > 0 {
+				diffTicks := diffCounter(load.GetTotalTime(), lastLoad.GetTotalTime())
+				process.CPUPercentage = 100 * float64(diffTicks) / userHZ / elapsed
+			}
+		}
+
+		processes = append(processes, process)
+	}
+
+	sort.Slice(processes, func(i, j int) bool {
+		return processes[i].PID < processes[j].PID
+	})
+
+	d.processes = processes
+
+	logger.Instance().Debug("process usage updated", zap.Int("processCount", len(processes)))
+
+	return procLoadAccessor, nil
+}
+
+func (d *Domain) getCurrentProcLoad() (map[int]procLoad, error) {
+	entries, err := ioutil.ReadDir(d.cfg.ProcRootPath)
+	if err != nil {
+		return nil, fmt.Errorf("can't read proc root: %w", err)
+	}
+
+	var (
+		now              = time.Now()
+		procLoadAccessor = make(map[int]procLoad)
+	)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		load, err := d.getCurrentProcessLoad(pid, now)
+		if err != nil {
+			// The process may exit between listing and reading or be hidden from us, that's fine.
+			if errors.Is(err, os.ErrNotExist) || errors.Is(err, syscall.ESRCH) || errors.Is(err, syscall.EACCES) {
+				continue
+			}
+
+			return nil, fmt.Errorf("can't get current load of %d: %w", pid, err)
+		}
+
+		procLoadAccessor[pid] = *load
+	}
+	if len(procLoadAccessor) == 0 {
+		return nil, errors.New("processes not found")
+	}
+
+	return procLoadAccessor, nil
+}
+
+func (d *Domain) getCurrentProcessLoad(pid int, at time.Time) (*procLoad, error) {
+	pidPath := filepath.Join(d.cfg.ProcRootPath, strconv.Itoa(pid))
+
+	rawStat, err := loadMagicFile(filepath.Join(pidPath, procFileStat))
+	if err != nil {
+		return nil, fmt.Errorf("can't load magic file: %w", err)
+	}
+
+	load, err := extractProcLoad(rawStat)
+	if err != nil {
+		return nil, fmt.Errorf("can't extract proc load: %w", err)
+	}
+
+	rawStatus, err := loadMagicFile(filepath.Join(pidPath, procFileStatus))
+	if err != nil {
+		return nil, fmt.Errorf("can't load magic file: %w", err)
+	}
+
+	for _, line := range strings.Split(rawStatus, "\n") {
+		if !strings.HasPrefix(line, procStatusRSS+":") {
+			continue
+		}
+
+		_, rss, err := extractMemInfoRecord(line)
+		if err != nil {
+			return nil, fmt.Errorf("can't extract rss: %w", err)
+		}
+
+		load.RSSBytes = rss
+	}
+
+	load.At = at
+
+	return load, nil
+}
+
+func extractProcLoad(statLine string) (*procLoad, error) {
+	// The name is wrapped with parentheses and may contain spaces or parentheses itself.
+	var (
+		nameStartIdx = strings.IndexRune(statLine, '(')
+		nameEndIdx   = strings.LastIndex(statLine, ")")
+	)
+	if nameStartIdx == -1 || nameEndIdx < nameStartIdx {
+		return nil, fmt.Errorf("name not found in %q", statLine)
+	}
+
+	tokens := strings.FieldsFunc(statLine[nameEndIdx+1:], func(r rune) bool {
+		return unicode.IsSpace(r)
+	})
+	if len(tokens) < 20 {
+		return nil, fmt.Errorf("invalid length: %d", len(tokens))
+	}
+
+	// The tokens start with the 3rd field of the stat, see proc(5).
+	rawUserTime, rawSystemTime, rawStartTime := tokens[11], tokens[12], tokens[19]
+
+	userTime, err := strconv.ParseInt(rawUserTime, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid format of user time: %w", err)
+	}
+
+	systemTime, err := strconv.ParseInt(rawSystemTime, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid format of system time: %w", err)
+	}
+
+	startTime, err := strconv.ParseInt(rawStartTime, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid format of start time: %w", err)
+	}
+
+	return &procLoad{
+		Name:       statLine[nameStartIdx+1 : nameEndIdx],
+		UserTime:   userTime,
+		SystemTime: systemTime,
+		StartTime:  startTime,
+	}, nil
+}
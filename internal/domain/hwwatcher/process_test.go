@@ -0,0 +1,150 @@
+package hwwatcher
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGetCurrentProcLoad(t *testing.T) {
+	d := New(Config{ProcRootPath: fixtureDirPath}, nil)
+
+	procLoadAccessor, err := d.getCurrentProcLoad()
+	if err != nil {
+		t.Fatalf("getCurrentProcLoad() error = %v", err)
+	}
+
+	// The kernel threads have no rss, the net dir isn't a process.
+	want := map[int]procLoad{
+		1:    {Name: "systemd", UserTime: 1843, SystemTime: 2210, StartTime: 13, RSSBytes: 11480 * 1024},
+		812:  {Name: "wg-crypt-wg0", UserTime: 0, SystemTime: 5213, StartTime: 1204},
+		1044: {Name: "iino-service", UserTime: 7421, SystemTime: 3980, StartTime: 2210, RSSBytes: 18048 * 1024},
+	}
+	for pid, load := range procLoadAccessor {
+		load.At = time.Time{}
+		procLoadAccessor[pid] = load
+	}
+
+	if !reflect.DeepEqual(procLoadAccessor, want) {
+		t.Errorf("getCurrentProcLoad() = %+v, want %+v", procLoadAccessor, want)
+	}
+}
+
+func TestUpdateProcessUsage(t *testing.T) {
+	d := New(Config{ProcRootPath: fixtureDirPath}, nil)
+
+	procLoadAccessor, err := d.updateProcessUsage(nil)
+	if err != nil {
+		t.Fatalf("updateProcessUsage() error = %v", err)
+	}
+
+	if _, err = d.GetTopProcesses(ProcessSortByCPU, 0); err == nil {
+		t.Fatal("GetTopProcesses() without the last load, want error")
+	}
+
+	// The last load is two seconds older, the init is behind by a core second and the service pid is reused.
+	lastProcLoad := make(map[int]procLoad, len(procLoadAccessor))
+	for pid, load := range procLoadAccessor {
+		load.At = load.At.Add(-2 * time.Second)
+		lastProcLoad[pid] = load
+	}
+
+	initLoad := lastProcLoad[1]
+	initLoad.UserTime -= userHZ
+	lastProcLoad[1] = initLoad
+
+	serviceLoad := lastProcLoad[1044]
+	serviceLoad.StartTime--
+	serviceLoad.UserTime -= userHZ
+	lastProcLoad[1044] = serviceLoad
+
+	if _, err = d.updateProcessUsage(lastProcLoad); err != nil {
+		t.Fatalf("updateProcessUsage() error = %v", err)
+	}
+
+	processes, err := d.GetTopProcesses(ProcessSortByCPU, 0)
+	if err != nil {
+		t.Fatalf("GetTopProcesses() error = %v", err)
+	}
+
+	if len(processes) != 3 || processes[0].PID != 1 {
+		t.Fatalf("GetTopProcesses() = %+v, want the init first", processes)
+	}
+
+	// The fixtures are read again, so a bit more than two seconds pass.
+	if got := processes[0].CPUPercentage; got > 50 || got < 45 {
+		t.Errorf("init cpu percentage = %f, want about 50", got)
+	}
+
+	for _, process := range processes[1:] {
+		if process.CPUPercentage != 0 {
+			t.Errorf("%q cpu percentage = %f, want 0", process.Name, process.CPUPercentage)
+		}
+	}
+
+	processes, err = d.GetTopProcesses(ProcessSortByMemory, 2)
+	if err != nil {
+		t.Fatalf("GetTopProcesses() error = %v", err)
+	}
+
+	if len(processes) != 2 || processes[0].PID != 1044 || processes[1].PID != 1 {
+		t.Errorf("GetTopProcesses() by memory = %+v, want the service and the init", processes)
+	}
+}
+
+func TestExtractProcLoad(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		wantName string
+		wantErr  bool
+	}{
+		{
+			name:     "plain name",
+			line:     "1 (systemd) S 0 1 1 0 -1 4194560 89241 1866453 96 1021 1843 2210 9132 4120 20 0 1 0 13 172834816 2870",
+			wantName: "systemd",
+		},
+		{
+			name:     "name with parentheses and spaces",
+			line:     "1 (a) b (c)) S 0 1 1 0 -1 4194560 89241 1866453 96 1021 1843 2210 9132 4120 20 0 1 0 13 172834816 2870",
+			wantName: "a) b (c)",
+		},
+		{
+			name:    "no name",
+			line:    "1 systemd S 0 1 1 0 -1 4194560 89241 1866453 96 1021 1843 2210 9132 4120 20 0 1 0 13 172834816 2870",
+			wantErr: true,
+		},
+		{
+			name:    "short",
+			line:    "1 (systemd) S 0 1 1 0 -1 4194560",
+			wantErr: true,
+		},
+		{
+			name:    "malformed time",
+			line:    "1 (systemd) S 0 1 1 0 -1 4194560 89241 1866453 96 1021 long 2210 9132 4120 20 0 1 0 13 172834816 2870",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractProcLoad(tt.line)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("extractProcLoad() = %+v, want error", got)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("extractProcLoad() error = %v", err)
+			}
+
+			want := procLoad{Name: tt.wantName, UserTime: 1843, SystemTime: 2210, StartTime: 13}
+			if *got != want {
+				t.Errorf("extractProcLoad() = %+v, want %+v", *got, want)
+			}
+		})
+	}
+}
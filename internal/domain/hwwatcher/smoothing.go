@@ -0,0 +1,117 @@
+package hwwatcher
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	smoothingRaw = "raw"
+	smoothingSMA = "sma"
+	smoothingEMA = "ema"
+)
+
+type cpuSmoother interface {
+	// Smooth mixes the sample into the history of the core and returns the smoothed value.
+	Smooth(slug string, sample cpuCoreShare) cpuCoreShare
+	// Forget drops the history of the core.
+	Forget(slug string)
+}
+
+func newCPUSmoother(cfg Config) cpuSmoother {
+	switch cfg.CPUSmoothing {
+	case smoothingSMA:
+		return &smaSmoother{
+			window:  cfg.CPUSmoothingWindow,
+			samples: make(map[string][]cpuCoreShare),
+		}
+	case smoothingEMA:
+		return &emaSmoother{
+			alpha: cfg.CPUSmoothingAlpha,
+			last:  make(map[string]cpuCoreShare),
+		}
+	default:
+		return &rawSmoother{}
+	}
+}
+
+func validateSmoothing(cfg Config) error {
+	switch cfg.CPUSmoothing {
+	case smoothingRaw:
+		return nil
+	case smoothingSMA:
+		if cfg.CPUSmoothingWindow < 1 {
+			return fmt.Errorf("invalid smoothing window: %d", cfg.CPUSmoothingWindow)
+		}
+
+		return nil
+	case smoothingEMA:
+		if cfg.CPUSmoothingAlpha <= 0 || cfg.CPUSmoothingAlpha > 1 {
+			return fmt.Errorf("invalid smoothing alpha: %f", cfg.CPUSmoothingAlpha)
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("unknown smoothing: %q", cfg.CPUSmoothing)
+	}
+}
+
+type rawSmoother struct{}
+
+func (s *rawSmoother) Smooth(_ string, sample cpuCoreShare) cpuCoreShare {
+	return sample
+}
+
+func (s *rawSmoother) Forget(_ string) {}
+
+// smaSmoother is a simple moving average over the last window samples.
+type smaSmoother struct {
+	window  int
+	samples map[string][]cpuCoreShare
+}
+
+func (s *smaSmoother) Smooth(slug string, sample cpuCoreShare) cpuCoreShare {
+	samples := append(s.samples[slug], sample)
+	if len(samples) > s.window {
+		samples = samples[len(samples)-s.window:]
+	}
+	s.samples[slug] = samples
+
+	var sum cpuCoreShare
+	for _, sample := range samples {
+		sum = sum.Add(sample)
+	}
+
+	return sum.Scale(1 / float64(len(samples)))
+}
+
+func (s *smaSmoother) Forget(slug string) {
+	delete(s.samples, slug)
+}
+
+// emaSmoother is an exponential moving average, alpha is a weight of the newest sample.
+type emaSmoother struct {
+	alpha float64
+	last  map[string]cpuCoreShare
+}
+
+func (s *emaSmoother) Smooth(slug string, sample cpuCoreShare) cpuCoreShare {
+	last, found := s.last[slug]
+	if !found {
+		s.last[slug] = sample
+		return sample
+	}
+
+	smoothed := sample.Scale(s.alpha).Add(last.Scale(1 - s.alpha))
+	s.last[slug] = smoothed
+
+	return smoothed
+}
+
+func (s *emaSmoother) Forget(slug string) {
+	delete(s.last, slug)
+}
+
+func roundPercentage(share float64) int64 {
+	return int64(math.Round(share))
+}
@@ -0,0 +1,147 @@
+package hwwatcher
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.uber.org/zap"
+
+	"github.com/whiteforestz/iino/internal/pkg/logger"
+)
+
+const (
+	statKeyContextSwitches = "ctxt"
+	statKeyBootTime        = "btime"
+	statKeyProcesses       = "processes"
+	statKeyProcsRunning    = "procs_running"
+	statKeyProcsBlocked    = "procs_blocked"
+)
+
+var (
+	statKeys = []string{
+		statKeyContextSwitches,
+		statKeyBootTime,
+		statKeyProcesses,
+		statKeyProcsRunning,
+		statKeyProcsBlocked,
+	}
+)
+
+func (d *Domain) updateSystemUsage(lastSystemLoad *systemLoad, statRecords map[string]int64) (*systemLoad, error) {
+	if statRecords == nil {
+		return nil, errors.New("stat records aren't read")
+	}
+
+	sysLoad, err := d.getCurrentSystemLoad(statRecords)
+	if err != nil {
+		return nil, fmt.Errorf("can't get current system load: %w", err)
+	}
+
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	if lastSystemLoad == nil {
+		return sysLoad, nil
+	}
+
+	elapsed := sysLoad.At.Sub(lastSystemLoad.At).Seconds()
+	if elapsed <= 0 {
+		return sysLoad, nil
+	}
+
+	systemUsage := SystemUsage{
+		Load1:        sysLoad.Load1,
+		Load5:        sysLoad.Load5,
+		Load15:       sysLoad.Load15,
+		ProcsRunning: sysLoad.ProcsRunning,
+		ProcsBlocked: sysLoad.ProcsBlocked,
+		BootedAt:     time.Unix(sysLoad.BootTimeUnix, 0),
+		Uptime:       sysLoad.Uptime,
+
+		ContextSwitchesPerSecond: int64(float64(diffCounter(sysLoad.ContextSwitches, lastSystemLoad.ContextSwitches)) / elapsed),
+		ForksPerSecond:           int64(float64(diffCounter(sysLoad.Processes, lastSystemLoad.Processes)) / elapsed),
+	}
+
+	d.usage.System = &systemUsage
+
+	logger.Instance().Debug("system usage updated", zap.Any("systemUsage", systemUsage))
+
+	return sysLoad, nil
+}
+
+func (d *Domain) getCurrentSystemLoad(statRecords map[string]int64) (*systemLoad, error) {
+	now := time.Now()
+
+	loadAvg, err := d.getCurrentLoadAvg()
+	if err != nil {
+		return nil, fmt.Errorf("can't get current load avg: %w", err)
+	}
+
+	uptime, err := d.getCurrentUptime()
+	if err != nil {
+		return nil, fmt.Errorf("can't get current uptime: %w", err)
+	}
+
+	return &systemLoad{
+		At:              now,
+		Load1:           loadAvg[0],
+		Load5:           loadAvg[1],
+		Load15:          loadAvg[2],
+		Uptime:          uptime,
+		BootTimeUnix:    statRecords[statKeyBootTime],
+		ContextSwitches: statRecords[statKeyContextSwitches],
+		Processes:       statRecords[statKeyProcesses],
+		ProcsRunning:    statRecords[statKeyProcsRunning],
+		ProcsBlocked:    statRecords[statKeyProcsBlocked],
+	}, nil
+}
+
+func (d *Domain) getCurrentLoadAvg() ([3]float64, error) {
+	var loadAvg [3]float64
+
+	raw, err := loadMagicFile(d.cfg.LoadAvgSourcePath)
+	if err != nil {
+		return loadAvg, fmt.Errorf("can't load magic file: %w", err)
+	}
+
+	tokens := strings.FieldsFunc(raw, func(r rune) bool {
+		return unicode.IsSpace(r)
+	})
+	if len(tokens) != 5 {
+		return loadAvg, fmt.Errorf("invalid length: %d", len(tokens))
+	}
+
+	for idx := range loadAvg {
+		loadAvg[idx], err = strconv.ParseFloat(tokens[idx], 64)
+		if err != nil {
+			return loadAvg, fmt.Errorf("invalid format at %d: %w", idx, err)
+		}
+	}
+
+	return loadAvg, nil
+}
+
+func (d *Domain) getCurrentUptime() (time.Duration, error) {
+	raw, err := loadMagicFile(d.cfg.UptimeSourcePath)
+	if err != nil {
+		return 0, fmt.Errorf("can't load magic file: %w", err)
+	}
+
+	tokens := strings.FieldsFunc(raw, func(r rune) bool {
+		return unicode.IsSpace(r)
+	})
+	if len(tokens) == 0 {
+		return 0, errors.New("uptime not found")
+	}
+
+	seconds, err := strconv.ParseFloat(tokens[0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid format: %w", err)
+	}
+
+	return time.Duration(seconds * float64(time.Second)), nil
+}
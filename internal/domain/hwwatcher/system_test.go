@@ -0,0 +1,134 @@
+package hwwatcher
+
+import (
+	"os"
+	"path"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newSystemFixtureDomain() *Domain {
+	return New(Config{
+		CPULoadSourcePath: fixtureDirPath + "/stat",
+		LoadAvgSourcePath: fixtureDirPath + "/loadavg",
+		UptimeSourcePath:  fixtureDirPath + "/uptime",
+	}, nil)
+}
+
+func TestGetCurrentCPULoad(t *testing.T) {
+	d := newSystemFixtureDomain()
+
+	cpuLoad, statRecords, err := d.getCurrentCPULoad()
+	if err != nil {
+		t.Fatalf("getCurrentCPULoad() error = %v", err)
+	}
+
+	wantCPULoad := []cpuCoreLoad{
+		{Slug: "cpu", User: 19683, Nice: 70, System: 109618, Idle: 169863594, IOWait: 1019, SoftIRq: 50250, Steal: 125301},
+		{Slug: "cpu0", User: 9230, Nice: 35, System: 43509, Idle: 85138623, IOWait: 634, SoftIRq: 12482, Steal: 12752},
+		{Slug: "cpu1", User: 10453, Nice: 34, System: 66108, Idle: 84724970, IOWait: 384, SoftIRq: 37767, Steal: 112548},
+	}
+	if !reflect.DeepEqual(cpuLoad, wantCPULoad) {
+		t.Errorf("getCurrentCPULoad() cores = %+v, want %+v", cpuLoad, wantCPULoad)
+	}
+
+	// The intr and softirq lines have more tokens than a record, so they are skipped.
+	wantStatRecords := map[string]int64{
+		statKeyContextSwitches: 117590501,
+		statKeyBootTime:        1646503006,
+		statKeyProcesses:       32949,
+		statKeyProcsRunning:    1,
+		statKeyProcsBlocked:    0,
+	}
+	if !reflect.DeepEqual(statRecords, wantStatRecords) {
+		t.Errorf("getCurrentCPULoad() records = %v, want %v", statRecords, wantStatRecords)
+	}
+}
+
+func TestGetCurrentCPULoadMissedRecord(t *testing.T) {
+	raw, err := os.ReadFile(fixtureDirPath + "/stat")
+	if err != nil {
+		t.Fatalf("can't read fixture: %v", err)
+	}
+
+	p := path.Join(t.TempDir(), "stat")
+	if err = os.WriteFile(p, raw[:len(raw)/2], 0o600); err != nil {
+		t.Fatalf("can't write stat: %v", err)
+	}
+
+	d := New(Config{CPULoadSourcePath: p}, nil)
+	if _, _, err = d.getCurrentCPULoad(); err == nil {
+		t.Error("getCurrentCPULoad() of the cut stat, want error")
+	}
+}
+
+func TestGetCurrentSystemLoad(t *testing.T) {
+	d := newSystemFixtureDomain()
+
+	loadAvg, err := d.getCurrentLoadAvg()
+	if err != nil {
+		t.Fatalf("getCurrentLoadAvg() error = %v", err)
+	}
+
+	if want := [3]float64{0.42, 0.35, 0.31}; loadAvg != want {
+		t.Errorf("getCurrentLoadAvg() = %v, want %v", loadAvg, want)
+	}
+
+	uptime, err := d.getCurrentUptime()
+	if err != nil {
+		t.Fatalf("getCurrentUptime() error = %v", err)
+	}
+
+	if want := 967661*time.Second + 420*time.Millisecond; uptime.Round(time.Millisecond) != want {
+		t.Errorf("getCurrentUptime() = %s, want %s", uptime, want)
+	}
+}
+
+func TestUpdateSystemUsage(t *testing.T) {
+	d := newSystemFixtureDomain()
+
+	_, statRecords, err := d.getCurrentCPULoad()
+	if err != nil {
+		t.Fatalf("getCurrentCPULoad() error = %v", err)
+	}
+
+	if _, err = d.updateSystemUsage(nil, nil); err == nil {
+		t.Fatal("updateSystemUsage() without the stat records, want error")
+	}
+
+	sysLoad, err := d.updateSystemUsage(nil, statRecords)
+	if err != nil {
+		t.Fatalf("updateSystemUsage() error = %v", err)
+	}
+
+	if d.usage.System != nil {
+		t.Fatalf("updateSystemUsage() without the last load = %+v, want none", d.usage.System)
+	}
+
+	// The last load is two seconds older and behind by 2000 switches and 20 forks.
+	lastSystemLoad := *sysLoad
+	lastSystemLoad.At = sysLoad.At.Add(-2 * time.Second)
+	lastSystemLoad.ContextSwitches -= 2000
+	lastSystemLoad.Processes -= 20
+
+	if _, err = d.updateSystemUsage(&lastSystemLoad, statRecords); err != nil {
+		t.Fatalf("updateSystemUsage() error = %v", err)
+	}
+
+	got := d.usage.System
+	if got == nil {
+		t.Fatal("updateSystemUsage() usage is missed")
+	}
+
+	if got.Load1 != 0.42 || got.Load5 != 0.35 || got.Load15 != 0.31 ||
+		got.ProcsRunning != 1 || got.ProcsBlocked != 0 || !got.BootedAt.Equal(time.Unix(1646503006, 0)) {
+		t.Errorf("updateSystemUsage() = %+v", got)
+	}
+
+	// The files are read again, so a bit more than two seconds pass.
+	if got.ContextSwitchesPerSecond > 1000 || got.ContextSwitchesPerSecond < 900 ||
+		got.ForksPerSecond > 10 || got.ForksPerSecond < 9 {
+		t.Errorf("updateSystemUsage() = %+v, want about 1000 switches and 10 forks per second", got)
+	}
+}
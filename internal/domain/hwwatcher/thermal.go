@@ -0,0 +1,156 @@
+package hwwatcher
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/whiteforestz/iino/internal/pkg/logger"
+)
+
+const (
+	thermalZonePattern = "class/thermal/thermal_zone*"
+	hwmonPattern       = "class/hwmon/hwmon*"
+	hwmonInputPattern  = "temp*_input"
+
+	thermalFileType  = "type"
+	thermalFileTemp  = "temp"
+	hwmonFileName    = "name"
+	hwmonInputSuffix = "_input"
+	hwmonLabelSuffix = "_label"
+
+	milliDegreesPerDegree = 1000
+)
+
+func (d *Domain) updateThermalUsage() error {
+	thermalZones, err := d.getCurrentThermalZones()
+	if err != nil {
+		return fmt.Errorf("can't get current thermal zones: %w", err)
+	}
+
+	hwmonSensors, err := d.getCurrentHWMonSensors()
+	if err != nil {
+		return fmt.Errorf("can't get current hwmon sensors: %w", err)
+	}
+
+	thermalUsage := append(thermalZones, hwmonSensors...)
+
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	d.usage.Thermal = thermalUsage
+
+	logger.Instance().Debug("thermal usage updated", zap.Any("thermalUsage", thermalUsage))
+
+	return nil
+}
+
+func (d *Domain) getCurrentThermalZones() ([]ThermalZoneUsage, error) {
+	zonePaths, err := filepath.Glob(filepath.Join(d.cfg.SysfsRootPath, thermalZonePattern))
+	if err != nil {
+		return nil, fmt.Errorf("can't glob thermal zones: %w", err)
+	}
+	sort.Strings(zonePaths)
+
+	thermalZones := make([]ThermalZoneUsage, 0, len(zonePaths))
+	for _, zonePath := range zonePaths {
+		zoneType, err := loadSysfsValue(filepath.Join(zonePath, thermalFileType))
+		if err != nil {
+			return nil, fmt.Errorf("can't load zone type: %w", err)
+		}
+
+		celsius, err := loadSysfsTemperature(filepath.Join(zonePath, thermalFileTemp))
+		if err != nil {
+			// Some zones refuse to be read while the sensor is powered down.
+			logger.Instance().Debug("can't load zone temperature", zap.String("zone", zonePath), zap.Error(err))
+			continue
+		}
+
+		thermalZones = append(thermalZones, ThermalZoneUsage{
+			Label:   zoneType,
+			Celsius: celsius,
+		})
+	}
+
+	return thermalZones, nil
+}
+
+func (d *Domain) getCurrentHWMonSensors() ([]ThermalZoneUsage, error) {
+	hwmonPaths, err := filepath.Glob(filepath.Join(d.cfg.SysfsRootPath, hwmonPattern))
+	if err != nil {
+		return nil, fmt.Errorf("can't glob hwmon: %w", err)
+	}
+	sort.Strings(hwmonPaths)
+
+	var hwmonSensors []ThermalZoneUsage
+	for _, hwmonPath := range hwmonPaths {
+		chipName, err := loadSysfsValue(filepath.Join(hwmonPath, hwmonFileName))
+		if err != nil {
+			return nil, fmt.Errorf("can't load chip name: %w", err)
+		}
+
+		inputPaths, err := filepath.Glob(filepath.Join(hwmonPath, hwmonInputPattern))
+		if err != nil {
+			return nil, fmt.Errorf("can't glob hwmon inputs: %w", err)
+		}
+		sort.Strings(inputPaths)
+
+		for _, inputPath := range inputPaths {
+			celsius, err := loadSysfsTemperature(inputPath)
+			if err != nil {
+				logger.Instance().Debug("can't load sensor temperature", zap.String("sensor", inputPath), zap.Error(err))
+				continue
+			}
+
+			var (
+				sensorSlug = strings.TrimSuffix(filepath.Base(inputPath), hwmonInputSuffix)
+				labelPath  = filepath.Join(hwmonPath, sensorSlug+hwmonLabelSuffix)
+			)
+
+			label, err := loadSysfsValue(labelPath)
+			if err != nil {
+				if !errors.Is(err, os.ErrNotExist) {
+					return nil, fmt.Errorf("can't load sensor label: %w", err)
+				}
+
+				label = sensorSlug
+			}
+
+			hwmonSensors = append(hwmonSensors, ThermalZoneUsage{
+				Label:   fmt.Sprintf("%s/%s", chipName, label),
+				Celsius: celsius,
+			})
+		}
+	}
+
+	return hwmonSensors, nil
+}
+
+func loadSysfsTemperature(path string) (float64, error) {
+	raw, err := loadSysfsValue(path)
+	if err != nil {
+		return 0, err
+	}
+
+	milliDegrees, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid format of %q: %w", path, err)
+	}
+
+	return float64(milliDegrees) / milliDegreesPerDegree, nil
+}
+
+func loadSysfsValue(path string) (string, error) {
+	raw, err := loadMagicFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(raw), nil
+}
@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sync"
 )
 
 const (
@@ -21,6 +22,7 @@ type Domain struct {
 	cfg Config
 
 	prepared bool
+	mux      *sync.Mutex
 	tags     map[string]string
 }
 
@@ -30,6 +32,7 @@ func New(
 	return &Domain{
 		cfg: cfg,
 
+		mux:  &sync.Mutex{},
 		tags: make(map[string]string),
 	}
 }
@@ -45,7 +48,10 @@ func (d *Domain) Prepare() error {
 		return fmt.Errorf("can't mk tmp root dir: %w", err)
 	}
 
+	d.mux.Lock()
 	d.tags[tagRoot] = path
+	d.mux.Unlock()
+
 	d.prepared = true
 
 	return nil
@@ -54,7 +60,10 @@ func (d *Domain) Prepare() error {
 func (d *Domain) Clean() error {
 	d.guard()
 
+	d.mux.Lock()
 	rootPath, found := d.tags[tagRoot]
+	d.mux.Unlock()
+
 	if !found {
 		return errors.New("root tag not found")
 	}
@@ -118,6 +127,10 @@ func (d *Domain) prepareTag(tag string) (string, error) {
 		return "", errors.New("invalid tag")
 	}
 
+	// The domains save from their own loops, so the tags are shared between the goroutines.
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
 	if tagPath, found := d.tags[tag]; found {
 		return tagPath, nil
 	}
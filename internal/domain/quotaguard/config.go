@@ -0,0 +1,81 @@
+package quotaguard
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+var (
+	byteSizeUnitAccessor = map[string]int64{
+		"":    1,
+		"B":   1,
+		"KiB": 1 << 10,
+		"MiB": 1 << 20,
+		"GiB": 1 << 30,
+		"TiB": 1 << 40,
+	}
+)
+
+type Config struct {
+	Limits     map[string]string `split_words:"true"`
+	Thresholds []int             `split_words:"true" default:"80,100"`
+	Enforce    bool              `split_words:"true"`
+
+	LimitBytes map[string]int64
+}
+
+func MustNewConfig() Config {
+	var cfg Config
+	envconfig.MustProcess("quota", &cfg)
+
+	cfg.LimitBytes = make(map[string]int64, len(cfg.Limits))
+	for name, rawLimit := range cfg.Limits {
+		limit, err := parseByteSize(rawLimit)
+		if err != nil {
+			panic(fmt.Sprintf("invalid limit of %q: %s", name, err))
+		}
+
+		cfg.LimitBytes[name] = limit
+	}
+
+	for _, threshold := range cfg.Thresholds {
+		if threshold <= 0 {
+			panic(fmt.Sprintf("invalid threshold: %d", threshold))
+		}
+	}
+	sort.Ints(cfg.Thresholds)
+
+	return cfg
+}
+
+// parseByteSize parses sizes like 512MiB or 50GiB, a plain number is bytes.
+func parseByteSize(raw string) (int64, error) {
+	raw = strings.TrimSpace(raw)
+
+	unitIdx := strings.IndexFunc(raw, func(r rune) bool {
+		return r < '0' || r > '9'
+	})
+	if unitIdx == -1 {
+		unitIdx = len(raw)
+	}
+
+	n, err := strconv.ParseInt(raw[:unitIdx], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid format: %w", err)
+	}
+
+	multiplier, found := byteSizeUnitAccessor[raw[unitIdx:]]
+	if !found {
+		return 0, fmt.Errorf("unknown unit: %q", raw[unitIdx:])
+	}
+
+	if n <= 0 {
+		return 0, fmt.Errorf("non-positive size: %d", n)
+	}
+
+	return n * multiplier, nil
+}
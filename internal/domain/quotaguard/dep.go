@@ -0,0 +1,30 @@
+package quotaguard
+
+import (
+	"context"
+
+	"github.com/whiteforestz/iino/internal/domain/trafficledger"
+	"github.com/whiteforestz/iino/internal/domain/wgwatcher"
+)
+
+type PersistorDomain interface {
+	Save(tag string, b []byte) error
+	Load(tag string) ([]byte, error)
+}
+
+type TrafficLedgerDomain interface {
+	GetTraffic(name, period string) (*trafficledger.Traffic, error)
+}
+
+type WGWatcherDomain interface {
+	RemovePeer(ctx context.Context, name string) (*wgwatcher.PeerRef, error)
+	AddPeer(ctx context.Context, ref wgwatcher.PeerRef) error
+}
+
+type WGProvisionerDomain interface {
+	IsSuspended(name string) bool
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, text string) error
+}
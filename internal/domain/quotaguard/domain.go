@@ -0,0 +1,103 @@
+package quotaguard
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/whiteforestz/iino/internal/pkg/infloop"
+	"github.com/whiteforestz/iino/internal/pkg/logger"
+)
+
+const (
+	tickerPeriod = 10 * time.Second
+)
+
+type Domain struct {
+	started   chan struct{}
+	finished  chan struct{}
+	cfg       Config
+	persistor PersistorDomain
+	ledger    TrafficLedgerDomain
+	wgWatcher WGWatcherDomain
+	wgProv    WGProvisionerDomain
+	notifier  Notifier
+
+	prepared             bool
+	mux                  *sync.RWMutex
+	snapshotPeerAccessor map[string]snapshotPeer
+}
+
+func New(
+	cfg Config,
+	persistorDomain PersistorDomain,
+	trafficLedgerDomain TrafficLedgerDomain,
+	wgWatcherDomain WGWatcherDomain,
+	wgProvisionerDomain WGProvisionerDomain,
+	notifier Notifier,
+) *Domain {
+	return &Domain{
+		started:   make(chan struct{}),
+		finished:  make(chan struct{}),
+		cfg:       cfg,
+		persistor: persistorDomain,
+		ledger:    trafficLedgerDomain,
+		wgWatcher: wgWatcherDomain,
+		wgProv:    wgProvisionerDomain,
+		notifier:  notifier,
+
+		mux: &sync.RWMutex{},
+	}
+}
+
+func (d *Domain) Prepare() error {
+	var err error
+
+	d.snapshotPeerAccessor, err = d.loadSnapshotPeerAccessor()
+	if err != nil {
+		return fmt.Errorf("can't load snapshot peer accessor: %w", err)
+	}
+
+	d.prepared = true
+
+	return nil
+}
+
+func (d *Domain) Listen(ctx context.Context) {
+	d.guard()
+
+	go d.loop(ctx)
+	<-d.started
+}
+
+func (d *Domain) Wait() {
+	<-d.finished
+}
+
+func (d *Domain) loop(ctx context.Context) {
+	ticker := time.NewTicker(tickerPeriod)
+	defer ticker.Stop()
+
+	infloop.InfLoop(ctx, ticker, infloop.Caller{
+		OnStart: func(_ context.Context) {
+			close(d.started)
+		},
+		OnFinish: func(_ context.Context) {
+			close(d.finished)
+		},
+		OnTick: func(ctx context.Context) {
+			if err := d.updateQuotas(ctx); err != nil {
+				logger.Instance().Error("can't update quotas", zap.Error(err))
+			}
+		},
+	})
+}
+
+func (d *Domain) guard() {
+	if !d.prepared {
+		panic("unprepared domain")
+	}
+}
@@ -0,0 +1,153 @@
+package quotaguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"go.uber.org/zap"
+
+	"github.com/whiteforestz/iino/internal/domain/trafficledger"
+	"github.com/whiteforestz/iino/internal/domain/wgwatcher"
+	"github.com/whiteforestz/iino/internal/pkg/humanize"
+	"github.com/whiteforestz/iino/internal/pkg/logger"
+)
+
+const (
+	timeFormat = "2006-01-02 15:04"
+)
+
+func (d *Domain) updateQuotas(ctx context.Context) error {
+	names := make([]string, 0, len(d.cfg.LimitBytes))
+	for name := range d.cfg.LimitBytes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	var changed bool
+	for _, name := range names {
+		peerChanged, err := d.updateQuota(ctx, name, d.cfg.LimitBytes[name])
+		if err != nil {
+			logger.Instance().Error("can't update quota", zap.String("peer", name), zap.Error(err))
+		}
+
+		changed = changed || peerChanged
+	}
+
+	if !changed {
+		return nil
+	}
+
+	if err := d.saveSnapshotPeerAccessor(d.snapshotPeerAccessor); err != nil {
+		return fmt.Errorf("can't flush quotas: %w", err)
+	}
+
+	return nil
+}
+
+func (d *Domain) updateQuota(ctx context.Context, name string, limit int64) (bool, error) {
+	traffic, err := d.ledger.GetTraffic(name, "")
+	if err != nil {
+		if errors.Is(err, trafficledger.ErrUnknownPeer) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("can't get traffic: %w", err)
+	}
+
+	var (
+		snapshot = d.snapshotPeerAccessor[name]
+		changed  bool
+	)
+
+	if snapshot.Period != traffic.Period {
+		if snapshot.Disabled != nil {
+			if err = d.enablePeer(ctx, name, *snapshot.Disabled); err != nil {
+				return false, fmt.Errorf("can't enable peer back: %w", err)
+			}
+		}
+
+		snapshot = snapshotPeer{
+			Period: traffic.Period,
+		}
+		changed = true
+	}
+
+	used := traffic.TransferRx + traffic.TransferTx
+
+	var reached int
+	for _, threshold := range d.cfg.Thresholds {
+		if used*100 >= int64(threshold)*limit {
+			reached = threshold
+		}
+	}
+
+	if reached > snapshot.Threshold {
+		err = d.notify(ctx, fmt.Sprintf(
+			"⚠️ %s has used %d%% of the quota: %s of %s until %s",
+			name,
+			used*100/limit,
+			humanize.Bytes(used),
+			humanize.Bytes(limit),
+			traffic.To.Format(timeFormat),
+		))
+		if err == nil {
+			snapshot.Threshold = reached
+			changed = true
+		}
+	}
+
+	if d.cfg.Enforce && used >= limit {
+		// The peer is removed again if the interface has been restarted with it.
+		ref, err := d.wgWatcher.RemovePeer(ctx, name)
+		switch {
+		case errors.Is(err, wgwatcher.ErrUnknownPeer):
+		case err != nil:
+			logger.Instance().Error("can't disable peer", zap.String("peer", name), zap.Error(err))
+		case snapshot.Disabled == nil:
+			snapshot.Disabled = ref
+			changed = true
+
+			_ = d.notify(ctx, fmt.Sprintf("⛔️ %s is disabled until %s", name, traffic.To.Format(timeFormat)))
+		}
+	}
+
+	d.snapshotPeerAccessor[name] = snapshot
+
+	return changed, nil
+}
+
+// enablePeer adds the disabled peer back on the renewal of the quota. The suspended peer is up
+// to the provisioner, it's added back once the suspension is lifted. The revoked peer has no conf left,
+// so it isn't enabled back either.
+func (d *Domain) enablePeer(ctx context.Context, name string, ref wgwatcher.PeerRef) error {
+	if d.wgProv.IsSuspended(name) {
+		logger.Instance().Info("disabled peer stays suspended", zap.String("peer", name))
+		return nil
+	}
+
+	err := d.wgWatcher.AddPeer(ctx, ref)
+	switch {
+	case errors.Is(err, wgwatcher.ErrUnknownPeer):
+		logger.Instance().Info("disabled peer is gone", zap.String("peer", name), zap.Error(err))
+	case err != nil:
+		return err
+	default:
+		_ = d.notify(ctx, fmt.Sprintf("✅ %s is enabled back, the quota is renewed", name))
+	}
+
+	return nil
+}
+
+func (d *Domain) notify(ctx context.Context, text string) error {
+	err := d.notifier.Notify(ctx, text)
+	if err != nil {
+		logger.Instance().Error("can't notify", zap.Error(err))
+	}
+
+	return err
+}
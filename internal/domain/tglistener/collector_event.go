@@ -0,0 +1,51 @@
+package tglistener
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/whiteforestz/iino/internal/pkg/eventbus"
+	"github.com/whiteforestz/iino/internal/pkg/logger"
+)
+
+// notifyCollectorFailures drains the failures, they're drained even if the notifications are off,
+// otherwise the bus would complain about the full buffer. The failure isn't reported again
+// within the cooldown, the failed notification is retried on the next failure.
+func (d *Domain) notifyCollectorFailures(
+	ctx context.Context,
+	events <-chan eventbus.CollectorFailed,
+	notifiedAccessor map[string]time.Time,
+) {
+	for {
+		select {
+		case event := <-events:
+			if !d.cfg.CollectorEvents {
+				continue
+			}
+
+			key := event.Source + "/" + event.Collector
+			if notifiedAt, found := notifiedAccessor[key]; found && event.At.Sub(notifiedAt) < d.cfg.CollectorEventsCooldown {
+				continue
+			}
+
+			if err := d.Notify(ctx, formatCollectorFailure(event)); err != nil {
+				if !isTimeout(err) {
+					logger.Instance().Error("can't notify collector failure", zap.String("collector", key), zap.Error(err))
+				}
+
+				continue
+			}
+
+			notifiedAccessor[key] = event.At
+		default:
+			return
+		}
+	}
+}
+
+func formatCollectorFailure(event eventbus.CollectorFailed) string {
+	return fmt.Sprintf("⚠️ %s can't collect %s: %s", event.Source, event.Collector, event.Err)
+}
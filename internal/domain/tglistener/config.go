@@ -1,17 +1,37 @@
 package tglistener
 
 import (
+	"time"
+
 	"github.com/kelseyhightower/envconfig"
 )
 
 type Config struct {
 	APIToken string `split_words:"true"`
 	AdminID  int64  `split_words:"true"`
+
+	// PeerEvents enables the notifications of the peers coming online and going offline, the notification
+	// is sent once the peer stays in the new state for PeerEventsDebounce, so flapping peers are quiet.
+	PeerEvents         bool          `split_words:"true"`
+	PeerEventsDebounce time.Duration `split_words:"true" default:"1m"`
+	PeerEventsMuted    []string      `split_words:"true"`
+
+	// CollectorEvents enables the notifications of the failed collectors of the watchers, the failing collector
+	// fails on every tick, so the admin is notified of it once per CollectorEventsCooldown.
+	CollectorEvents         bool          `split_words:"true"`
+	CollectorEventsCooldown time.Duration `split_words:"true" default:"1h"`
+
+	PeerEventsMutedAccessor map[string]struct{}
 }
 
 func MustNewConfig() Config {
 	var cfg Config
 	envconfig.MustProcess("tg", &cfg)
 
+	cfg.PeerEventsMutedAccessor = make(map[string]struct{}, len(cfg.PeerEventsMuted))
+	for _, name := range cfg.PeerEventsMuted {
+		cfg.PeerEventsMutedAccessor[name] = struct{}{}
+	}
+
 	return cfg
 }
@@ -1,20 +1,52 @@
 package tglistener
 
 import (
+	"context"
 	"net/http"
+	"time"
 
 	"github.com/whiteforestz/iino/internal/domain/hwwatcher"
+	"github.com/whiteforestz/iino/internal/domain/trafficledger"
+	"github.com/whiteforestz/iino/internal/domain/wgprovisioner"
 	"github.com/whiteforestz/iino/internal/domain/wgwatcher"
+	"github.com/whiteforestz/iino/internal/pkg/eventbus"
 )
 
 type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
+// PeerStateSubscriber receives the peer state changes of the watcher, it's closed once the events are read no more.
+type PeerStateSubscriber interface {
+	Events() <-chan wgwatcher.PeerStateChanged
+	Close()
+}
+
+// CollectorFailureSubscriber receives the failures of the watcher collectors, it's closed along with PeerStateSubscriber.
+type CollectorFailureSubscriber interface {
+	Events() <-chan eventbus.CollectorFailed
+	Close()
+}
+
 type HWWatcherDomain interface {
 	GetUsage() (*hwwatcher.Usage, error)
+	GetStats(window time.Duration) (*hwwatcher.Stats, error)
+	GetTopProcesses(sortBy string, limit int) ([]hwwatcher.ProcessUsage, error)
 }
 
 type WGWatcherDomain interface {
 	GetUsage() (*wgwatcher.Usage, error)
 }
+
+type TrafficLedgerDomain interface {
+	GetTraffic(name, period string) (*trafficledger.Traffic, error)
+}
+
+type WGProvisionerDomain interface {
+	AddPeer(ctx context.Context, name string) (*wgprovisioner.ProvisionedPeer, error)
+	GetPeerConf(name string) (*wgprovisioner.ProvisionedPeer, error)
+	Revoke(ctx context.Context, name string) (*wgprovisioner.Revocation, error)
+	Suspend(ctx context.Context, name string, duration time.Duration) (*wgprovisioner.Suspension, error)
+	Resume(ctx context.Context, name string) error
+	GetSuspensions() []wgprovisioner.Suspension
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"mime/multipart"
 	"net/http"
 	"os"
 	"time"
@@ -24,37 +25,58 @@ const (
 )
 
 type Domain struct {
-	started    chan struct{}
-	finished   chan struct{}
-	cfg        Config
-	httpClient HTTPClient
-	hwWatcher  HWWatcherDomain
-	wgWatcher  WGWatcherDomain
+	started        chan struct{}
+	finished       chan struct{}
+	eventsStarted  chan struct{}
+	eventsFinished chan struct{}
+	cfg            Config
+	httpClient     HTTPClient
+	peerState      PeerStateSubscriber
+	collectorFail  CollectorFailureSubscriber
+	hwWatcher      HWWatcherDomain
+	wgWatcher      WGWatcherDomain
+	ledger         TrafficLedgerDomain
+	wgProv         WGProvisionerDomain
 }
 
 func New(
 	cfg Config,
 	httpClient HTTPClient,
+	peerStateSubscriber PeerStateSubscriber,
+	collectorFailureSubscriber CollectorFailureSubscriber,
 	hwWatcherDomain HWWatcherDomain,
 	wgWatcherDomain WGWatcherDomain,
+	trafficLedgerDomain TrafficLedgerDomain,
+	wgProvisionerDomain WGProvisionerDomain,
 ) *Domain {
 	return &Domain{
-		started:    make(chan struct{}),
-		finished:   make(chan struct{}),
-		cfg:        cfg,
-		httpClient: httpClient,
-		hwWatcher:  hwWatcherDomain,
-		wgWatcher:  wgWatcherDomain,
+		started:        make(chan struct{}),
+		finished:       make(chan struct{}),
+		eventsStarted:  make(chan struct{}),
+		eventsFinished: make(chan struct{}),
+		cfg:            cfg,
+		httpClient:     httpClient,
+		peerState:      peerStateSubscriber,
+		collectorFail:  collectorFailureSubscriber,
+		hwWatcher:      hwWatcherDomain,
+		wgWatcher:      wgWatcherDomain,
+		ledger:         trafficLedgerDomain,
+		wgProv:         wgProvisionerDomain,
 	}
 }
 
 func (d *Domain) Listen(ctx context.Context) {
 	go d.loop(ctx)
 	<-d.started
+
+	// The events are read apart from the updates, since getting the updates blocks for the long poll.
+	go d.eventLoop(ctx)
+	<-d.eventsStarted
 }
 
 func (d *Domain) Wait() {
 	<-d.finished
+	<-d.eventsFinished
 }
 
 func (d *Domain) loop(ctx context.Context) {
@@ -82,11 +104,20 @@ func (d *Domain) loop(ctx context.Context) {
 			}
 
 			for _, update := range updates {
+				// The commands changing the peers are acknowledged before they're run, so the failed
+				// reply never runs them again.
+				if d.changesPeers(update) {
+					lastUpdateID = update.UpdateID
+				}
+
 				if err = d.handleUpdate(ctx, update); err != nil {
-					if !isTimeout(err) {
-						logger.Instance().Error("can't handle update", zap.Error(err))
+					// The timed out update is handled again on the next tick, any other failure
+					// would repeat forever, so the update is skipped then.
+					if isTimeout(err) {
+						break
 					}
-					break
+
+					logger.Instance().Error("can't handle update", zap.Int64("updateID", update.UpdateID), zap.Error(err))
 				}
 
 				lastUpdateID = update.UpdateID
@@ -107,6 +138,43 @@ func (d *Domain) performRequest(ctx context.Context, host string, in, out interf
 	}
 	req.Header.Set("Content-Type", "application/json")
 
+	return d.doRequest(req, out)
+}
+
+// performMultipartRequest posts the fields along with the file, it's the only way to upload files.
+func (d *Domain) performMultipartRequest(ctx context.Context, host string, fields map[string]string, file dtoFile, out interface{}) error {
+	var body bytes.Buffer
+
+	w := multipart.NewWriter(&body)
+	for k, v := range fields {
+		if err := w.WriteField(k, v); err != nil {
+			return fmt.Errorf("can't write field %q: %w", k, err)
+		}
+	}
+
+	fw, err := w.CreateFormFile(file.Field, file.Name)
+	if err != nil {
+		return fmt.Errorf("can't create file: %w", err)
+	}
+
+	if _, err = fw.Write(file.Content); err != nil {
+		return fmt.Errorf("can't write file: %w", err)
+	}
+
+	if err = w.Close(); err != nil {
+		return fmt.Errorf("can't close writer: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, host, &body)
+	if err != nil {
+		return fmt.Errorf("can't create req: %w", err)
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+
+	return d.doRequest(req, out)
+}
+
+func (d *Domain) doRequest(req *http.Request, out interface{}) error {
 	resp, err := d.httpClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("can't do req: %w", err)
@@ -2,35 +2,27 @@ package tglistener
 
 import (
 	"fmt"
+	"net"
+	"strings"
 	"time"
+
+	"github.com/whiteforestz/iino/internal/domain/hwwatcher"
+	"github.com/whiteforestz/iino/internal/domain/wgwatcher"
+	"github.com/whiteforestz/iino/internal/pkg/humanize"
 )
 
 const (
 	defaultTimeFormat = "2006-01-02 15:04"
 
-	activityStatusOffline         = "offline"
-	activityStatusOnline          = "online"
-	activityStatusOnlineThreshold = 2 * time.Minute
-
-	memorySizeThreshold = 1000
-)
+	activityStatusOffline = "offline"
+	activityStatusOnline  = "online"
 
-var (
-	memoryUnitSlugAccessor = map[int64]string{
-		0: "B",
-		1: "KiB",
-		2: "MiB",
-		3: "GiB",
-		4: "TiB",
-		5: "PiB",
-		6: "EiB",
-		7: "ZiB",
-		8: "YiB",
-	}
+	markdownV2Reserved     = "_*[]()~`>#+-=|{}.!\\"
+	markdownV2CodeReserved = "`\\"
 )
 
 func formatActivityStatus(nowUnix, latestHandshakeUnix int64) string {
-	if (nowUnix - latestHandshakeUnix) < int64(activityStatusOnlineThreshold.Seconds()) {
+	if wgwatcher.IsOnline(nowUnix, latestHandshakeUnix) {
 		return activityStatusOnline
 	}
 
@@ -41,21 +33,54 @@ func formatLatestActivity(latestHandshakeUnix int64) string {
 	return time.Unix(latestHandshakeUnix, 0).Format(defaultTimeFormat)
 }
 
-func formatMemorySize(bytes int64) string {
-	var (
-		order int64
-		n     = float64(bytes)
-	)
+// formatEndpointHost drops the port of the endpoint, the IPv6 brackets are dropped too.
+func formatEndpointHost(endpoint string) string {
+	host, _, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return endpoint
+	}
+
+	return host
+}
 
-	for n > memorySizeThreshold {
-		n /= 1024
-		order++
+// formatSuspensionUntil formats the end of the suspension, the zero time means until resumed.
+func formatSuspensionUntil(until time.Time) string {
+	if until.IsZero() {
+		return "until resumed"
 	}
 
-	slug, found := memoryUnitSlugAccessor[order]
-	if !found {
-		return "unknown 🗿"
+	return fmt.Sprintf("until `%s`", until.Format(defaultTimeFormat))
+}
+
+func formatMetricValue(unit string, value float64) string {
+	switch unit {
+	case hwwatcher.MetricUnitPercent:
+		return fmt.Sprintf("%.0f%%", value)
+	case hwwatcher.MetricUnitBytesPerSecond:
+		return fmt.Sprintf("%s/s", humanize.Bytes(int64(value)))
+	case hwwatcher.MetricUnitCelsius:
+		return fmt.Sprintf("%.1f %s", value, unit)
+	default:
+		return fmt.Sprintf("%.2f", value)
+	}
+}
+
+// escapeMarkdownV2 escapes the text of the user for MarkdownV2, only the backticks and backslashes
+// are reserved inside the code spans, Telegram rejects the whole message otherwise.
+func escapeMarkdownV2(text string, inCode bool) string {
+	reserved := markdownV2Reserved
+	if inCode {
+		reserved = markdownV2CodeReserved
+	}
+
+	var b strings.Builder
+	for _, r := range text {
+		if strings.ContainsRune(reserved, r) {
+			b.WriteRune('\\')
+		}
+
+		b.WriteRune(r)
 	}
 
-	return fmt.Sprintf("%.2f %s", n, slug)
+	return b.String()
 }
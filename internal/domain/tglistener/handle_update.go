@@ -3,11 +3,24 @@ package tglistener
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
 )
 
 const (
 	cmdHWUsage = "/hwusage"
 	cmdWGUsage = "/wgusage"
+	cmdTop     = "/top"
+	cmdTraffic = "/traffic"
+	cmdAddPeer = "/addpeer"
+	cmdConf    = "/conf"
+	cmdRevoke  = "/revoke"
+	cmdSuspend = "/suspend"
+	cmdResume  = "/resume"
+
+	cmdMentionSeparator = "@"
 )
 
 func (d *Domain) handleUpdate(ctx context.Context, update dtoUpdate) error {
@@ -15,22 +28,54 @@ func (d *Domain) handleUpdate(ctx context.Context, update dtoUpdate) error {
 		return nil
 	}
 
+	cmd, args := parseCommand(update.Message.Text)
+
 	var err error
-	switch update.Message.Text {
+	switch cmd {
 	case cmdHWUsage:
-		_, err = d.sendHWUsageMessage(ctx)
+		_, err = d.sendHWUsageMessage(ctx, args)
 	case cmdWGUsage:
-		_, err = d.sendWGUsageMessage(ctx)
+		_, err = d.sendWGUsageMessage(ctx, args)
+	case cmdTop:
+		_, err = d.sendTopMessage(ctx, args)
+	case cmdTraffic:
+		_, err = d.sendTrafficMessage(ctx, args)
+	case cmdAddPeer:
+		_, err = d.sendAddPeerMessage(ctx, args)
+	case cmdConf:
+		_, err = d.sendConfMessage(ctx, args)
+	case cmdRevoke:
+		_, err = d.sendRevokeMessage(ctx, args)
+	case cmdSuspend:
+		_, err = d.sendSuspendMessage(ctx, args)
+	case cmdResume:
+		_, err = d.sendResumeMessage(ctx, args)
 	default:
 		_, err = d.sendHelpMessage(ctx)
 	}
 	if err != nil {
-		return fmt.Errorf("can't send help message: %w", err)
+		return fmt.Errorf("can't handle %q: %w", cmd, err)
 	}
 
 	return nil
 }
 
+// changesPeers reports whether the update is the command changing the peers, such an update
+// mustn't be handled twice even if the reply has failed.
+func (d *Domain) changesPeers(update dtoUpdate) bool {
+	if d.shouldSkipUpdate(update) {
+		return false
+	}
+
+	cmd, _ := parseCommand(update.Message.Text)
+	switch cmd {
+	case cmdAddPeer, cmdRevoke, cmdSuspend, cmdResume:
+		return true
+	default:
+		return false
+	}
+}
+
 func (d *Domain) shouldSkipUpdate(update dtoUpdate) bool {
 	if update.Message == nil || update.Message.From == nil {
 		return true
@@ -46,3 +91,65 @@ func (d *Domain) shouldSkipUpdate(update dtoUpdate) bool {
 
 	return false
 }
+
+func parseCommand(text string) (string, []string) {
+	tokens := strings.FieldsFunc(text, func(r rune) bool {
+		return unicode.IsSpace(r)
+	})
+	if len(tokens) == 0 {
+		return "", nil
+	}
+
+	cmd := tokens[0]
+	if idx := strings.Index(cmd, cmdMentionSeparator); idx != -1 {
+		cmd = cmd[:idx]
+	}
+
+	return strings.ToLower(cmd), tokens[1:]
+}
+
+func hasArg(args []string, arg string) bool {
+	for _, a := range args {
+		if strings.EqualFold(a, arg) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func findWindowArg(args []string) (time.Duration, bool) {
+	for _, arg := range args {
+		window, err := time.ParseDuration(arg)
+		if err == nil {
+			return window, true
+		}
+	}
+
+	return 0, false
+}
+
+// parseDurationArg parses the optional duration, it has to be positive if it's present.
+func parseDurationArg(args []string) (time.Duration, bool) {
+	if len(args) == 0 {
+		return 0, true
+	}
+
+	duration, err := time.ParseDuration(args[0])
+	if err != nil || duration <= 0 {
+		return 0, false
+	}
+
+	return duration, true
+}
+
+func findNumberArg(args []string) (int, bool) {
+	for _, arg := range args {
+		n, err := strconv.Atoi(arg)
+		if err == nil {
+			return n, true
+		}
+	}
+
+	return 0, false
+}
@@ -28,6 +28,17 @@ type sendMessageIn struct {
 
 type sendMessageOut dtoMessage
 
+type sendFileOut struct {
+	Result *dtoMessage
+}
+
+// dtoFile is the file of the multipart request, the field is the name of the method param, e.g. document.
+type dtoFile struct {
+	Field   string
+	Name    string
+	Content []byte
+}
+
 type dtoUpdate struct {
 	UpdateID int64       `json:"update_id"`
 	Message  *dtoMessage `json:"message,omitempty"`
@@ -0,0 +1,126 @@
+package tglistener
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/whiteforestz/iino/internal/domain/wgwatcher"
+	"github.com/whiteforestz/iino/internal/pkg/infloop"
+	"github.com/whiteforestz/iino/internal/pkg/logger"
+)
+
+const (
+	eventTickPeriod = 1 * time.Second
+)
+
+// pendingPeerEvent is the latest event of the peer waiting out the debounce, wasOnline is the state
+// the admin knows about, so the peer back to it within the debounce isn't reported at all.
+type pendingPeerEvent struct {
+	event     wgwatcher.PeerStateChanged
+	wasOnline bool
+}
+
+func (d *Domain) eventLoop(ctx context.Context) {
+	ticker := time.NewTicker(eventTickPeriod)
+	defer ticker.Stop()
+
+	var (
+		pendingAccessor           = make(map[string]pendingPeerEvent)
+		notifiedAccessor          = make(map[string]bool)
+		notifiedCollectorAccessor = make(map[string]time.Time)
+	)
+
+	infloop.InfLoop(ctx, ticker, infloop.Caller{
+		OnStart: func(_ context.Context) {
+			close(d.eventsStarted)
+		},
+		OnFinish: func(_ context.Context) {
+			d.peerState.Close()
+			d.collectorFail.Close()
+			close(d.eventsFinished)
+		},
+		OnTick: func(ctx context.Context) {
+			d.queuePeerEvents(d.peerState.Events(), pendingAccessor, notifiedAccessor)
+			d.flushPeerEvents(ctx, time.Now(), pendingAccessor, notifiedAccessor)
+			d.notifyCollectorFailures(ctx, d.collectorFail.Events(), notifiedCollectorAccessor)
+		},
+	})
+}
+
+// queuePeerEvents drains the events, they're drained even if the notifications are off,
+// otherwise the bus would complain about the full buffer.
+func (d *Domain) queuePeerEvents(
+	events <-chan wgwatcher.PeerStateChanged,
+	pendingAccessor map[string]pendingPeerEvent,
+	notifiedAccessor map[string]bool,
+) {
+	for {
+		select {
+		case event := <-events:
+			if !d.cfg.PeerEvents {
+				continue
+			}
+
+			if _, muted := d.cfg.PeerEventsMutedAccessor[event.Name]; muted {
+				continue
+			}
+
+			pending, found := pendingAccessor[event.Name]
+			if !found {
+				pending.wasOnline = !event.Online
+				if wasOnline, notified := notifiedAccessor[event.Name]; notified {
+					pending.wasOnline = wasOnline
+				}
+			}
+
+			pending.event = event
+			pendingAccessor[event.Name] = pending
+		default:
+			return
+		}
+	}
+}
+
+func (d *Domain) flushPeerEvents(
+	ctx context.Context,
+	now time.Time,
+	pendingAccessor map[string]pendingPeerEvent,
+	notifiedAccessor map[string]bool,
+) {
+	debounceSeconds := int64(d.cfg.PeerEventsDebounce.Seconds())
+
+	for name, pending := range pendingAccessor {
+		if now.Unix()-pending.event.AtUnix < debounceSeconds {
+			continue
+		}
+
+		if pending.event.Online != pending.wasOnline {
+			if err := d.Notify(ctx, formatPeerEvent(pending.event)); err != nil {
+				if !isTimeout(err) {
+					logger.Instance().Error("can't notify peer event", zap.String("name", name), zap.Error(err))
+				}
+
+				// The event is retried on the next tick.
+				continue
+			}
+		}
+
+		notifiedAccessor[name] = pending.event.Online
+		delete(pendingAccessor, name)
+	}
+}
+
+func formatPeerEvent(event wgwatcher.PeerStateChanged) string {
+	if !event.Online {
+		return fmt.Sprintf("🔴 %s went offline", event.Name)
+	}
+
+	if event.Endpoint == "" {
+		return fmt.Sprintf("🟢 %s came online", event.Name)
+	}
+
+	return fmt.Sprintf("🟢 %s came online from %s", event.Name, formatEndpointHost(event.Endpoint))
+}
@@ -0,0 +1,64 @@
+package tglistener
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	apiMethodSendDocument = "sendDocument"
+	apiMethodSendPhoto    = "sendPhoto"
+	timeoutSendFile       = 10 * time.Second
+
+	sendFileFieldDocument = "document"
+	sendFileFieldPhoto    = "photo"
+)
+
+// sendDocument uploads the file as is, unlike photos documents aren't recompressed.
+func (d *Domain) sendDocument(ctx context.Context, name string, content []byte, caption string) (*dtoMessage, error) {
+	return d.sendFile(ctx, apiMethodSendDocument, dtoFile{
+		Field:   sendFileFieldDocument,
+		Name:    name,
+		Content: content,
+	}, caption)
+}
+
+func (d *Domain) sendPhoto(ctx context.Context, name string, content []byte, caption string) (*dtoMessage, error) {
+	return d.sendFile(ctx, apiMethodSendPhoto, dtoFile{
+		Field:   sendFileFieldPhoto,
+		Name:    name,
+		Content: content,
+	}, caption)
+}
+
+func (d *Domain) sendFile(ctx context.Context, method string, file dtoFile, caption string) (*dtoMessage, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeoutSendFile)
+	defer cancel()
+
+	var (
+		host = fmt.Sprintf(apiHostTmpl, d.cfg.APIToken, method)
+
+		fields = map[string]string{
+			"chat_id":              strconv.FormatInt(d.cfg.AdminID, 10),
+			"disable_notification": "true",
+		}
+		out sendFileOut
+	)
+
+	if caption != "" {
+		fields["caption"] = caption
+		fields["parse_mode"] = sendMessageParseModeMarkdownV2
+	}
+
+	if err := d.performMultipartRequest(ctx, host, fields, file, &out); err != nil {
+		return nil, fmt.Errorf("can't perform request: %w", err)
+	}
+
+	if out.Result == nil {
+		return nil, fmt.Errorf("empty result")
+	}
+
+	return out.Result, nil
+}
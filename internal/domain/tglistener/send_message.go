@@ -7,21 +7,47 @@ import (
 	"strings"
 	"time"
 
+	"go.uber.org/zap"
+
 	"github.com/whiteforestz/iino/internal/domain/hwwatcher"
+	"github.com/whiteforestz/iino/internal/domain/trafficledger"
+	"github.com/whiteforestz/iino/internal/domain/wgprovisioner"
 	"github.com/whiteforestz/iino/internal/domain/wgwatcher"
+	"github.com/whiteforestz/iino/internal/pkg/humanize"
+	"github.com/whiteforestz/iino/internal/pkg/logger"
+	"github.com/whiteforestz/iino/internal/pkg/qrcode"
 )
 
 const (
 	apiMethodSendMessage = "sendMessage"
 	timeoutSendMessage   = 2 * time.Second
 
+	hwUsageArgDetailed = "detailed"
+	wgUsageArgDetailed = "detailed"
+
+	addPeerQRScale = 8
+
+	topDefaultLimit = 10
+	topMaxLimit     = 50
+
 	messageHelp = `
 🏷 *Commands*
-🔧 /hwusage \- returns hardware usage
-🥷🏻 /wgusage \- returns WireGuard usage`
+🔧 /hwusage \- returns hardware usage, add _detailed_ for CPU time breakdown or _1m_, _5m_, _1h_ for stats
+📈 /top \- returns top processes, add _mem_ to sort by memory and a number to change the limit
+🥷🏻 /wgusage \- returns WireGuard usage, add _detailed_ for endpoints and allowed IPs
+📊 /traffic NAME \- returns traffic of the peer for the billing cycle, add _today_, _yesterday_, _YYYY\-MM\-DD_ or _YYYY\-MM_ for another period
+🆕 /addpeer NAME \- adds the peer and returns its client conf with QR code
+📄 /conf NAME \- returns the client conf of the peer with QR code again
+🪓 /revoke NAME \- removes the peer for good and archives its client conf
+⏸ /suspend NAME \- removes the peer until resumed, add _1h_, _24h_ and so on to resume it automatically
+▶️ /resume NAME \- adds the suspended peer back`
 )
 
-func (d *Domain) sendHWUsageMessage(ctx context.Context) (*dtoMessage, error) {
+func (d *Domain) sendHWUsageMessage(ctx context.Context, args []string) (*dtoMessage, error) {
+	if window, found := findWindowArg(args); found {
+		return d.sendHWStatsMessage(ctx, window)
+	}
+
 	var b strings.Builder
 
 	usage, err := d.hwWatcher.GetUsage()
@@ -33,8 +59,104 @@ func (d *Domain) sendHWUsageMessage(ctx context.Context) (*dtoMessage, error) {
 		b.WriteString("🔧 Hardware usage is not found 🗿\n")
 	} else {
 		b.WriteString("🔧 *Hardware usage*\n")
-		for _, core := range usage.CPU {
-			b.WriteString(fmt.Sprintf("`%s` \\- %d%%\n", core.Slug, core.Percentage))
+		writeSystemUsage(&b, usage.System)
+		writeCPUUsage(&b, usage.CPU, hasArg(args, hwUsageArgDetailed))
+		writeCPUTopologyChange(&b, usage.CPUTopologyChange)
+		writeMemoryUsage(&b, usage.Memory)
+		writeDiskUsage(&b, usage.DiskSpace, usage.DiskIO)
+		writeNetworkUsage(&b, usage.Network)
+		writeThermalUsage(&b, usage.Thermal)
+	}
+
+	b.WriteString(messageHelp)
+
+	return d.sendMessage(ctx, sendMessageIn{
+		ChatID:              d.cfg.AdminID,
+		Text:                b.String(),
+		ParseMode:           sendMessageParseModeMarkdownV2,
+		DisableNotification: true,
+	})
+}
+
+func (d *Domain) sendHWStatsMessage(ctx context.Context, window time.Duration) (*dtoMessage, error) {
+	var b strings.Builder
+
+	stats, err := d.hwWatcher.GetStats(window)
+	if err != nil && !errors.Is(err, hwwatcher.ErrEmptyUsage) && !errors.Is(err, hwwatcher.ErrInvalidWindow) {
+		return nil, fmt.Errorf("can't get stats: %w", err)
+	}
+
+	switch {
+	case errors.Is(err, hwwatcher.ErrInvalidWindow):
+		b.WriteString(fmt.Sprintf("🔧 Hardware stats for `%s` are out of retention 🗿\n", window))
+	case errors.Is(err, hwwatcher.ErrEmptyUsage):
+		b.WriteString("🔧 Hardware stats are not found 🗿\n")
+	default:
+		b.WriteString(fmt.Sprintf("🔧 *Hardware stats* for `%s`\n", window))
+		b.WriteString(fmt.Sprintf(
+			"from `%s` to `%s`\n",
+			stats.From.Format(defaultTimeFormat),
+			stats.To.Format(defaultTimeFormat),
+		))
+		b.WriteString("⏤⏤⏤\n")
+		for _, metric := range stats.Metrics {
+			b.WriteString(fmt.Sprintf(
+				"`%s` \\- min `%s` avg `%s` max `%s` p95 `%s`\n",
+				metric.Name,
+				formatMetricValue(metric.Unit, metric.Min),
+				formatMetricValue(metric.Unit, metric.Avg),
+				formatMetricValue(metric.Unit, metric.Max),
+				formatMetricValue(metric.Unit, metric.P95),
+			))
+		}
+	}
+
+	b.WriteString(messageHelp)
+
+	return d.sendMessage(ctx, sendMessageIn{
+		ChatID:              d.cfg.AdminID,
+		Text:                b.String(),
+		ParseMode:           sendMessageParseModeMarkdownV2,
+		DisableNotification: true,
+	})
+}
+
+func (d *Domain) sendTopMessage(ctx context.Context, args []string) (*dtoMessage, error) {
+	var (
+		b strings.Builder
+
+		sortBy = hwwatcher.ProcessSortByCPU
+		limit  = topDefaultLimit
+	)
+
+	if hasArg(args, hwwatcher.ProcessSortByMemory) {
+		sortBy = hwwatcher.ProcessSortByMemory
+	}
+
+	if n, found := findNumberArg(args); found && n > 0 {
+		limit = n
+		if limit > topMaxLimit {
+			limit = topMaxLimit
+		}
+	}
+
+	processes, err := d.hwWatcher.GetTopProcesses(sortBy, limit)
+	if err != nil && !errors.Is(err, hwwatcher.ErrEmptyUsage) {
+		return nil, fmt.Errorf("can't get top processes: %w", err)
+	}
+
+	if errors.Is(err, hwwatcher.ErrEmptyUsage) {
+		b.WriteString("📈 Processes are not found 🗿\n")
+	} else {
+		b.WriteString(fmt.Sprintf("📈 *Top processes* by `%s`\n", sortBy))
+		for _, process := range processes {
+			b.WriteString(fmt.Sprintf(
+				"`%d` `%s` \\- cpu `%.1f%%`, rss `%s`\n",
+				process.PID,
+				process.Name,
+				process.CPUPercentage,
+				humanize.Bytes(process.RSSBytes),
+			))
 		}
 	}
 
@@ -48,7 +170,7 @@ func (d *Domain) sendHWUsageMessage(ctx context.Context) (*dtoMessage, error) {
 	})
 }
 
-func (d *Domain) sendWGUsageMessage(ctx context.Context) (*dtoMessage, error) {
+func (d *Domain) sendWGUsageMessage(ctx context.Context, args []string) (*dtoMessage, error) {
 	var b strings.Builder
 
 	usage, err := d.wgWatcher.GetUsage()
@@ -62,27 +184,290 @@ func (d *Domain) sendWGUsageMessage(ctx context.Context) (*dtoMessage, error) {
 		nowUnix := time.Now().Unix()
 
 		b.WriteString("🥷🏻 *WireGuard usage*\n")
-		for _, peer := range usage.Peer {
-			b.WriteString("⏤⏤⏤\n")
+		for _, iface := range usage.Interface {
+			writeWGInterfaceUsage(&b, iface)
 
-			activityStatus := formatActivityStatus(nowUnix, peer.LatestHandshakeUnix)
-			b.WriteString(fmt.Sprintf("`%s` is `%s`\n", peer.Name, activityStatus))
+			var unmanagedPeers []wgwatcher.Peer
+			for _, peer := range iface.Peer {
+				if peer.Unmanaged {
+					unmanagedPeers = append(unmanagedPeers, peer)
+					continue
+				}
 
-			if peer.LatestHandshakeUnix != 0 {
-				handshakedAt := formatLatestActivity(peer.LatestHandshakeUnix)
-				b.WriteString(fmt.Sprintf("handshaked at `%s`\n", handshakedAt))
+				writeWGPeerUsage(&b, nowUnix, peer, hasArg(args, wgUsageArgDetailed))
 			}
 
-			if peer.TransferRx != 0 {
-				b.WriteString(fmt.Sprintf("received `%s`\n", formatMemorySize(peer.TransferRx)))
-			}
+			if len(unmanagedPeers) != 0 {
+				b.WriteString("⏤⏤⏤\n")
+				b.WriteString(fmt.Sprintf("👻 *Unmanaged peers* of `%s`, no client conf found\n", iface.Name))
 
-			if peer.TransferTx != 0 {
-				b.WriteString(fmt.Sprintf("sent `%s`\n", formatMemorySize(peer.TransferTx)))
+				for _, peer := range unmanagedPeers {
+					writeWGPeerUsage(&b, nowUnix, peer, hasArg(args, wgUsageArgDetailed))
+				}
 			}
 		}
 	}
 
+	if suspensions := d.wgProv.GetSuspensions(); len(suspensions) != 0 {
+		b.WriteString("⏤⏤⏤\n")
+		b.WriteString("⏸ *Suspended peers*\n")
+
+		for _, suspension := range suspensions {
+			b.WriteString(fmt.Sprintf("`%s` %s\n", escapeMarkdownV2(suspension.Name, true), formatSuspensionUntil(suspension.Until)))
+		}
+	}
+
+	b.WriteString(messageHelp)
+
+	return d.sendMessage(ctx, sendMessageIn{
+		ChatID:              d.cfg.AdminID,
+		Text:                b.String(),
+		ParseMode:           sendMessageParseModeMarkdownV2,
+		DisableNotification: true,
+	})
+}
+
+func (d *Domain) sendTrafficMessage(ctx context.Context, args []string) (*dtoMessage, error) {
+	var b strings.Builder
+
+	if len(args) == 0 {
+		return d.sendHelpMessage(ctx)
+	}
+
+	var (
+		name      = args[0]
+		rawPeriod string
+	)
+	if len(args) > 1 {
+		rawPeriod = args[1]
+	}
+
+	traffic, err := d.ledger.GetTraffic(name, rawPeriod)
+	switch {
+	case errors.Is(err, trafficledger.ErrUnknownPeer):
+		b.WriteString(fmt.Sprintf("📊 Traffic of `%s` is not found 🗿\n", escapeMarkdownV2(name, true)))
+	case errors.Is(err, trafficledger.ErrInvalidPeriod):
+		b.WriteString(fmt.Sprintf("📊 Period `%s` is unknown 🗿\n", escapeMarkdownV2(rawPeriod, true)))
+	case err != nil:
+		return nil, fmt.Errorf("can't get traffic: %w", err)
+	default:
+		b.WriteString(fmt.Sprintf("📊 *Traffic* of `%s` for `%s`\n", escapeMarkdownV2(traffic.Peer, true), traffic.Period))
+		b.WriteString(fmt.Sprintf(
+			"from `%s` to `%s`\n",
+			traffic.From.Format(defaultTimeFormat),
+			traffic.To.Format(defaultTimeFormat),
+		))
+		b.WriteString(fmt.Sprintf("received `%s`\n", humanize.Bytes(traffic.TransferRx)))
+		b.WriteString(fmt.Sprintf("sent `%s`\n", humanize.Bytes(traffic.TransferTx)))
+	}
+
+	b.WriteString(messageHelp)
+
+	return d.sendMessage(ctx, sendMessageIn{
+		ChatID:              d.cfg.AdminID,
+		Text:                b.String(),
+		ParseMode:           sendMessageParseModeMarkdownV2,
+		DisableNotification: true,
+	})
+}
+
+func (d *Domain) sendAddPeerMessage(ctx context.Context, args []string) (*dtoMessage, error) {
+	var b strings.Builder
+
+	if len(args) == 0 {
+		return d.sendHelpMessage(ctx)
+	}
+
+	name := args[0]
+
+	peer, err := d.wgProv.AddPeer(ctx, name)
+	switch {
+	case errors.Is(err, wgprovisioner.ErrDisabled):
+		b.WriteString("🆕 Provisioning is not configured 🗿\n")
+	case errors.Is(err, wgprovisioner.ErrInvalidName):
+		b.WriteString(fmt.Sprintf("🆕 Name `%s` is invalid or doesn't match the client conf pattern 🗿\n", escapeMarkdownV2(name, true)))
+	case errors.Is(err, wgprovisioner.ErrPeerExists):
+		b.WriteString(fmt.Sprintf("🆕 Peer `%s` already exists 🗿\n", escapeMarkdownV2(name, true)))
+	case errors.Is(err, wgprovisioner.ErrInterfaceDown):
+		b.WriteString("🆕 Interface is `down`, try again later 🗿\n")
+	case errors.Is(err, wgprovisioner.ErrSubnetExhausted):
+		b.WriteString("🆕 No free addresses left in the subnet 🗿\n")
+	case err != nil:
+		logger.Instance().Error("can't add peer", zap.String("name", name), zap.Error(err))
+		b.WriteString(fmt.Sprintf("🆕 Peer `%s` can't be added, see the logs 🗿\n", escapeMarkdownV2(name, true)))
+	default:
+		return d.sendProvisionedPeer(ctx, peer, fmt.Sprintf(
+			"🆕 Peer `%s` is added to `%s` with address `%s`",
+			escapeMarkdownV2(peer.Name, true), peer.Interface, peer.Address,
+		))
+	}
+
+	b.WriteString(messageHelp)
+
+	return d.sendMessage(ctx, sendMessageIn{
+		ChatID:              d.cfg.AdminID,
+		Text:                b.String(),
+		ParseMode:           sendMessageParseModeMarkdownV2,
+		DisableNotification: true,
+	})
+}
+
+func (d *Domain) sendConfMessage(ctx context.Context, args []string) (*dtoMessage, error) {
+	var b strings.Builder
+
+	if len(args) == 0 {
+		return d.sendHelpMessage(ctx)
+	}
+
+	name := args[0]
+
+	peer, err := d.wgProv.GetPeerConf(name)
+	switch {
+	case errors.Is(err, wgprovisioner.ErrUnknownPeer):
+		b.WriteString(fmt.Sprintf("📄 Client conf of `%s` is not found 🗿\n", escapeMarkdownV2(name, true)))
+	case err != nil:
+		logger.Instance().Error("can't get peer conf", zap.String("name", name), zap.Error(err))
+		b.WriteString(fmt.Sprintf("📄 Client conf of `%s` can't be read, see the logs 🗿\n", escapeMarkdownV2(name, true)))
+	default:
+		return d.sendProvisionedPeer(ctx, peer, fmt.Sprintf(
+			"📄 Client conf of `%s` at `%s` with address `%s`",
+			escapeMarkdownV2(peer.Name, true), peer.Interface, peer.Address,
+		))
+	}
+
+	b.WriteString(messageHelp)
+
+	return d.sendMessage(ctx, sendMessageIn{
+		ChatID:              d.cfg.AdminID,
+		Text:                b.String(),
+		ParseMode:           sendMessageParseModeMarkdownV2,
+		DisableNotification: true,
+	})
+}
+
+// sendProvisionedPeer sends the client conf for the desktop and the QR code of it for the mobile app.
+func (d *Domain) sendProvisionedPeer(ctx context.Context, peer *wgprovisioner.ProvisionedPeer, caption string) (*dtoMessage, error) {
+	msg, err := d.sendDocument(ctx, peer.ConfName, peer.Conf, caption)
+	if err != nil {
+		return nil, fmt.Errorf("can't send document: %w", err)
+	}
+
+	code, err := qrcode.Encode(peer.Conf, qrcode.LevelM)
+	if err != nil {
+		return nil, fmt.Errorf("can't encode qr code: %w", err)
+	}
+
+	img, err := code.PNG(addPeerQRScale)
+	if err != nil {
+		return nil, fmt.Errorf("can't render qr code: %w", err)
+	}
+
+	if _, err = d.sendPhoto(ctx, peer.Name+".png", img, "📷 Scan it with the WireGuard app"); err != nil {
+		return nil, fmt.Errorf("can't send photo: %w", err)
+	}
+
+	return msg, nil
+}
+
+func (d *Domain) sendRevokeMessage(ctx context.Context, args []string) (*dtoMessage, error) {
+	var b strings.Builder
+
+	if len(args) == 0 {
+		return d.sendHelpMessage(ctx)
+	}
+
+	name := args[0]
+
+	revocation, err := d.wgProv.Revoke(ctx, name)
+	switch {
+	case errors.Is(err, wgprovisioner.ErrDisabled):
+		b.WriteString("🪓 Revocation is not configured 🗿\n")
+	case errors.Is(err, wgprovisioner.ErrUnknownPeer):
+		b.WriteString(fmt.Sprintf("🪓 Peer `%s` is not found 🗿\n", escapeMarkdownV2(name, true)))
+	case err != nil:
+		logger.Instance().Error("can't revoke peer", zap.String("name", name), zap.Error(err))
+		b.WriteString(fmt.Sprintf("🪓 Peer `%s` can't be revoked, see the logs 🗿\n", escapeMarkdownV2(name, true)))
+	default:
+		b.WriteString(fmt.Sprintf("🪓 Peer `%s` is revoked from `%s`\n", escapeMarkdownV2(revocation.Name, true), revocation.Interface))
+		if revocation.ArchivedConfPath != "" {
+			b.WriteString(fmt.Sprintf("client conf is archived to `%s`\n", escapeMarkdownV2(revocation.ArchivedConfPath, true)))
+		}
+	}
+
+	b.WriteString(messageHelp)
+
+	return d.sendMessage(ctx, sendMessageIn{
+		ChatID:              d.cfg.AdminID,
+		Text:                b.String(),
+		ParseMode:           sendMessageParseModeMarkdownV2,
+		DisableNotification: true,
+	})
+}
+
+func (d *Domain) sendSuspendMessage(ctx context.Context, args []string) (*dtoMessage, error) {
+	var b strings.Builder
+
+	if len(args) == 0 {
+		return d.sendHelpMessage(ctx)
+	}
+
+	name := args[0]
+	duration, valid := parseDurationArg(args[1:])
+
+	var (
+		suspension *wgprovisioner.Suspension
+		err        error
+	)
+	if valid {
+		suspension, err = d.wgProv.Suspend(ctx, name, duration)
+	}
+
+	switch {
+	case !valid:
+		b.WriteString(fmt.Sprintf("⏸ Duration `%s` is invalid, use a positive one like `2h30m` 🗿\n", escapeMarkdownV2(args[1], true)))
+	case errors.Is(err, wgprovisioner.ErrUnknownPeer):
+		b.WriteString(fmt.Sprintf("⏸ Peer `%s` is not found 🗿\n", escapeMarkdownV2(name, true)))
+	case err != nil:
+		logger.Instance().Error("can't suspend peer", zap.String("name", name), zap.Error(err))
+		b.WriteString(fmt.Sprintf("⏸ Peer `%s` can't be suspended, see the logs 🗿\n", escapeMarkdownV2(name, true)))
+	default:
+		b.WriteString(fmt.Sprintf(
+			"⏸ Peer `%s` is suspended %s\n",
+			escapeMarkdownV2(suspension.Name, true),
+			formatSuspensionUntil(suspension.Until),
+		))
+	}
+
+	b.WriteString(messageHelp)
+
+	return d.sendMessage(ctx, sendMessageIn{
+		ChatID:              d.cfg.AdminID,
+		Text:                b.String(),
+		ParseMode:           sendMessageParseModeMarkdownV2,
+		DisableNotification: true,
+	})
+}
+
+func (d *Domain) sendResumeMessage(ctx context.Context, args []string) (*dtoMessage, error) {
+	var b strings.Builder
+
+	if len(args) == 0 {
+		return d.sendHelpMessage(ctx)
+	}
+
+	name := args[0]
+
+	err := d.wgProv.Resume(ctx, name)
+	switch {
+	case errors.Is(err, wgprovisioner.ErrNotSuspended):
+		b.WriteString(fmt.Sprintf("▶️ Peer `%s` is not suspended 🗿\n", escapeMarkdownV2(name, true)))
+	case err != nil:
+		logger.Instance().Error("can't resume peer", zap.String("name", name), zap.Error(err))
+		b.WriteString(fmt.Sprintf("▶️ Peer `%s` can't be resumed, see the logs 🗿\n", escapeMarkdownV2(name, true)))
+	default:
+		b.WriteString(fmt.Sprintf("▶️ Peer `%s` is resumed\n", escapeMarkdownV2(name, true)))
+	}
+
 	b.WriteString(messageHelp)
 
 	return d.sendMessage(ctx, sendMessageIn{
@@ -107,6 +492,19 @@ func (d *Domain) sendHelpMessage(ctx context.Context) (*dtoMessage, error) {
 	})
 }
 
+// Notify pushes the plain text to the admin, unlike replies to commands it makes a sound.
+func (d *Domain) Notify(ctx context.Context, text string) error {
+	_, err := d.sendMessage(ctx, sendMessageIn{
+		ChatID: d.cfg.AdminID,
+		Text:   text,
+	})
+	if err != nil {
+		return fmt.Errorf("can't send message: %w", err)
+	}
+
+	return nil
+}
+
 func (d *Domain) sendMessage(ctx context.Context, in sendMessageIn) (*dtoMessage, error) {
 	ctx, cancel := context.WithTimeout(ctx, timeoutSendMessage)
 	defer cancel()
@@ -123,3 +521,221 @@ func (d *Domain) sendMessage(ctx context.Context, in sendMessageIn) (*dtoMessage
 
 	return (*dtoMessage)(&out), nil
 }
+
+func writeWGInterfaceUsage(b *strings.Builder, iface wgwatcher.InterfaceUsage) {
+	b.WriteString("⏤⏤⏤\n")
+
+	if !iface.Up {
+		b.WriteString(fmt.Sprintf("🔻 `%s` is `down`", iface.Name))
+		if iface.DownSinceUnix != 0 {
+			b.WriteString(fmt.Sprintf(" since `%s`", formatLatestActivity(iface.DownSinceUnix)))
+		}
+		b.WriteString("\n")
+
+		return
+	}
+
+	b.WriteString(fmt.Sprintf("🔌 `%s` is `up`, port `%d`, peers `%d`\n", iface.Name, iface.ListenPort, iface.PeerCount))
+	b.WriteString(fmt.Sprintf("public key `%s`\n", iface.PublicKey))
+}
+
+func writeWGPeerUsage(b *strings.Builder, nowUnix int64, peer wgwatcher.Peer, detailed bool) {
+	b.WriteString("⏤⏤⏤\n")
+
+	activityStatus := formatActivityStatus(nowUnix, peer.LatestHandshakeUnix)
+	b.WriteString(fmt.Sprintf("`%s` is `%s`\n", peer.Name, activityStatus))
+
+	if peer.LatestHandshakeUnix != 0 {
+		handshakedAt := formatLatestActivity(peer.LatestHandshakeUnix)
+		b.WriteString(fmt.Sprintf("handshaked at `%s`\n", handshakedAt))
+	}
+
+	if peer.TransferRxTotal != 0 {
+		b.WriteString(fmt.Sprintf("received `%s`\n", humanize.Bytes(peer.TransferRxTotal)))
+	}
+
+	if peer.TransferTxTotal != 0 {
+		b.WriteString(fmt.Sprintf("sent `%s`\n", humanize.Bytes(peer.TransferTxTotal)))
+	}
+
+	if peer.TransferRxRateAvg != 0 || peer.TransferTxRateAvg != 0 {
+		b.WriteString(fmt.Sprintf(
+			"rate rx `%s/s` tx `%s/s`, avg rx `%s/s` tx `%s/s`\n",
+			humanize.Bytes(peer.TransferRxRate),
+			humanize.Bytes(peer.TransferTxRate),
+			humanize.Bytes(peer.TransferRxRateAvg),
+			humanize.Bytes(peer.TransferTxRateAvg),
+		))
+	}
+
+	if detailed {
+		writeWGPeerDetails(b, peer)
+	}
+}
+
+func writeWGPeerDetails(b *strings.Builder, peer wgwatcher.Peer) {
+	if peer.Endpoint != "" {
+		b.WriteString(fmt.Sprintf("endpoint `%s`\n", formatEndpointHost(peer.Endpoint)))
+	}
+
+	if peer.RoamCount != 0 && len(peer.EndpointHistory) != 0 {
+		latestChange := peer.EndpointHistory[len(peer.EndpointHistory)-1]
+		b.WriteString(fmt.Sprintf(
+			"roamed `%d` times, lately at `%s`\n",
+			peer.RoamCount,
+			formatLatestActivity(latestChange.ChangedAtUnix),
+		))
+	}
+
+	if len(peer.AllowedIPs) != 0 {
+		b.WriteString(fmt.Sprintf("allowed IPs `%s`\n", strings.Join(peer.AllowedIPs, ", ")))
+	}
+
+	if peer.PersistentKeepalive != 0 {
+		b.WriteString(fmt.Sprintf("keepalive `%s`\n", peer.PersistentKeepalive))
+	}
+}
+
+func writeSystemUsage(b *strings.Builder, systemUsage *hwwatcher.SystemUsage) {
+	if systemUsage == nil {
+		return
+	}
+
+	b.WriteString(fmt.Sprintf(
+		"`load` \\- `%.2f` `%.2f` `%.2f`\n",
+		systemUsage.Load1,
+		systemUsage.Load5,
+		systemUsage.Load15,
+	))
+	b.WriteString(fmt.Sprintf(
+		"`uptime` \\- `%s` since `%s`\n",
+		humanize.Duration(systemUsage.Uptime),
+		systemUsage.BootedAt.Format(defaultTimeFormat),
+	))
+	b.WriteString(fmt.Sprintf(
+		"`procs` \\- `%d` running, `%d` blocked\n",
+		systemUsage.ProcsRunning,
+		systemUsage.ProcsBlocked,
+	))
+	b.WriteString(fmt.Sprintf(
+		"`rates` \\- `%d` ctxt/s, `%d` forks/s\n",
+		systemUsage.ContextSwitchesPerSecond,
+		systemUsage.ForksPerSecond,
+	))
+	b.WriteString("⏤⏤⏤\n")
+}
+
+func writeCPUUsage(b *strings.Builder, cpuUsage []hwwatcher.CPUCoreUsage, detailed bool) {
+	for _, core := range cpuUsage {
+		b.WriteString(fmt.Sprintf("`%s` \\- %d%%\n", core.Slug, core.Percentage))
+
+		if detailed {
+			b.WriteString(fmt.Sprintf(
+				"usr %d%% nice %d%% sys %d%% iowait %d%% irq %d%% softirq %d%% steal %d%% guest %d%%\n",
+				core.Breakdown.User,
+				core.Breakdown.Nice,
+				core.Breakdown.System,
+				core.Breakdown.IOWait,
+				core.Breakdown.IRq,
+				core.Breakdown.SoftIRq,
+				core.Breakdown.Steal,
+				core.Breakdown.Guest+core.Breakdown.GuestNice,
+			))
+		}
+	}
+}
+
+func writeCPUTopologyChange(b *strings.Builder, topologyChange *hwwatcher.CPUTopologyChange) {
+	if topologyChange == nil {
+		return
+	}
+
+	b.WriteString(fmt.Sprintf("⚠️ core set changed at `%s`", topologyChange.At.Format(defaultTimeFormat)))
+	for _, slug := range topologyChange.Added {
+		b.WriteString(fmt.Sprintf(" \\+`%s`", slug))
+	}
+	for _, slug := range topologyChange.Removed {
+		b.WriteString(fmt.Sprintf(" \\-`%s`", slug))
+	}
+	b.WriteString("\n")
+}
+
+func writeMemoryUsage(b *strings.Builder, memoryUsage *hwwatcher.MemoryUsage) {
+	if memoryUsage == nil {
+		return
+	}
+
+	b.WriteString("⏤⏤⏤\n")
+	b.WriteString(fmt.Sprintf(
+		"`ram` \\- `%s` of `%s` available\n",
+		humanize.Bytes(memoryUsage.AvailableBytes),
+		humanize.Bytes(memoryUsage.TotalBytes),
+	))
+	b.WriteString(fmt.Sprintf(
+		"`buffers` \\- `%s`, `cached` \\- `%s`\n",
+		humanize.Bytes(memoryUsage.BuffersBytes),
+		humanize.Bytes(memoryUsage.CachedBytes),
+	))
+
+	if memoryUsage.SwapTotalBytes != 0 {
+		b.WriteString(fmt.Sprintf(
+			"`swap` \\- `%s` of `%s` used\n",
+			humanize.Bytes(memoryUsage.SwapUsedBytes),
+			humanize.Bytes(memoryUsage.SwapTotalBytes),
+		))
+	}
+}
+
+func writeDiskUsage(b *strings.Builder, diskSpaceUsage []hwwatcher.DiskSpaceUsage, diskIOUsage []hwwatcher.DiskIOUsage) {
+	if len(diskSpaceUsage) == 0 && len(diskIOUsage) == 0 {
+		return
+	}
+
+	b.WriteString("⏤⏤⏤\n")
+	for _, space := range diskSpaceUsage {
+		b.WriteString(fmt.Sprintf(
+			"`%s` \\- `%s` free of `%s`\n",
+			space.Mountpoint,
+			humanize.Bytes(space.FreeBytes),
+			humanize.Bytes(space.TotalBytes),
+		))
+	}
+
+	for _, io := range diskIOUsage {
+		b.WriteString(fmt.Sprintf(
+			"`%s` \\- read `%s/s`, write `%s/s`\n",
+			io.Device,
+			humanize.Bytes(io.ReadBytesPerSecond),
+			humanize.Bytes(io.WriteBytesPerSecond),
+		))
+	}
+}
+
+func writeNetworkUsage(b *strings.Builder, networkUsage []hwwatcher.NetworkInterfaceUsage) {
+	if len(networkUsage) == 0 {
+		return
+	}
+
+	b.WriteString("⏤⏤⏤\n")
+	for _, iface := range networkUsage {
+		b.WriteString(fmt.Sprintf(
+			"`%s` \\- rx `%s/s` \\(`%d` pkt/s\\), tx `%s/s` \\(`%d` pkt/s\\)\n",
+			iface.Name,
+			humanize.Bytes(iface.RxBytesPerSecond),
+			iface.RxPacketsPerSecond,
+			humanize.Bytes(iface.TxBytesPerSecond),
+			iface.TxPacketsPerSecond,
+		))
+	}
+}
+
+func writeThermalUsage(b *strings.Builder, thermalUsage []hwwatcher.ThermalZoneUsage) {
+	if len(thermalUsage) == 0 {
+		return
+	}
+
+	b.WriteString("⏤⏤⏤\n")
+	for _, zone := range thermalUsage {
+		b.WriteString(fmt.Sprintf("`%s` \\- `%.1f °C`\n", zone.Label, zone.Celsius))
+	}
+}
@@ -0,0 +1,37 @@
+package trafficledger
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+const (
+	maxBillingCycleStartDay = 28
+)
+
+type Config struct {
+	Timezone             string `split_words:"true" default:"UTC"`
+	BillingCycleStartDay int    `split_words:"true" default:"1"`
+	DailyRetentionDays   int    `split_words:"true" default:"92"`
+
+	Location *time.Location
+}
+
+func MustNewConfig() Config {
+	var cfg Config
+	envconfig.MustProcess("traffic", &cfg)
+
+	if cfg.BillingCycleStartDay < 1 || cfg.BillingCycleStartDay > maxBillingCycleStartDay {
+		panic(fmt.Sprintf("billing cycle start day must be in [1, %d]", maxBillingCycleStartDay))
+	}
+
+	location, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		panic(fmt.Sprintf("can't load timezone: %s", err))
+	}
+	cfg.Location = location
+
+	return cfg
+}
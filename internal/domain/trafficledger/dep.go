@@ -0,0 +1,14 @@
+package trafficledger
+
+import (
+	"github.com/whiteforestz/iino/internal/domain/wgwatcher"
+)
+
+type PersistorDomain interface {
+	Save(tag string, b []byte) error
+	Load(tag string) ([]byte, error)
+}
+
+type WGWatcherDomain interface {
+	GetUsage() (*wgwatcher.Usage, error)
+}
@@ -0,0 +1,128 @@
+package trafficledger
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/whiteforestz/iino/internal/pkg/infloop"
+	"github.com/whiteforestz/iino/internal/pkg/logger"
+)
+
+const (
+	tickerPeriod = 10 * time.Second
+)
+
+type Domain struct {
+	started   chan struct{}
+	finished  chan struct{}
+	cfg       Config
+	persistor PersistorDomain
+	wgWatcher WGWatcherDomain
+
+	prepared             bool
+	mux                  *sync.RWMutex
+	snapshotPeerAccessor map[string]snapshotPeer
+}
+
+func New(
+	cfg Config,
+	persistorDomain PersistorDomain,
+	wgWatcherDomain WGWatcherDomain,
+) *Domain {
+	return &Domain{
+		started:   make(chan struct{}),
+		finished:  make(chan struct{}),
+		cfg:       cfg,
+		persistor: persistorDomain,
+		wgWatcher: wgWatcherDomain,
+
+		mux: &sync.RWMutex{},
+	}
+}
+
+func (d *Domain) Prepare() error {
+	var err error
+
+	d.snapshotPeerAccessor, err = d.loadSnapshotPeerAccessor()
+	if err != nil {
+		return fmt.Errorf("can't load snapshot peer accessor: %w", err)
+	}
+
+	d.prepared = true
+
+	return nil
+}
+
+func (d *Domain) Listen(ctx context.Context) {
+	d.guard()
+
+	go d.loop(ctx)
+	<-d.started
+}
+
+func (d *Domain) Wait() {
+	<-d.finished
+}
+
+// GetTraffic returns traffic of the peer for the period, see parsePeriod for the supported formats.
+func (d *Domain) GetTraffic(name, rawPeriod string) (*Traffic, error) {
+	d.guard()
+
+	p, err := d.parsePeriod(rawPeriod, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	d.mux.RLock()
+	defer d.mux.RUnlock()
+
+	snapshot, found := d.snapshotPeerAccessor[name]
+	if !found {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownPeer, name)
+	}
+
+	var t transfer
+	if p.Daily {
+		t = snapshot.Daily[p.Key]
+	} else {
+		t = snapshot.Cycle[p.Key]
+	}
+
+	return &Traffic{
+		Peer:       name,
+		Period:     p.Key,
+		From:       p.From,
+		To:         p.To,
+		TransferRx: t.Rx,
+		TransferTx: t.Tx,
+	}, nil
+}
+
+func (d *Domain) loop(ctx context.Context) {
+	ticker := time.NewTicker(tickerPeriod)
+	defer ticker.Stop()
+
+	infloop.InfLoop(ctx, ticker, infloop.Caller{
+		OnStart: func(_ context.Context) {
+			close(d.started)
+		},
+		OnFinish: func(_ context.Context) {
+			close(d.finished)
+		},
+		OnTick: func(_ context.Context) {
+			if err := d.updateLedger(time.Now()); err != nil {
+				logger.Instance().Error("can't update ledger", zap.Error(err))
+			}
+		},
+	})
+}
+
+func (d *Domain) guard() {
+	if !d.prepared {
+		panic("unprepared domain")
+	}
+}
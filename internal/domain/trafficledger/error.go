@@ -0,0 +1,8 @@
+package trafficledger
+
+import "errors"
+
+var (
+	ErrUnknownPeer   = errors.New("unknown peer")
+	ErrInvalidPeriod = errors.New("invalid period")
+)
@@ -0,0 +1,178 @@
+package trafficledger
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/whiteforestz/iino/internal/domain/wgwatcher"
+)
+
+const (
+	dayKeyLayout   = "2006-01-02"
+	cycleKeyLayout = "2006-01"
+
+	periodCycle     = "month"
+	periodToday     = "today"
+	periodYesterday = "yesterday"
+)
+
+type period struct {
+	Key   string
+	Daily bool
+	From  time.Time
+	To    time.Time
+}
+
+func (d *Domain) updateLedger(now time.Time) error {
+	usage, err := d.wgWatcher.GetUsage()
+	if err != nil {
+		if errors.Is(err, wgwatcher.ErrEmptyUsage) {
+			return nil
+		}
+
+		return fmt.Errorf("can't get wg usage: %w", err)
+	}
+
+	var (
+		day   = d.dayPeriod(now)
+		cycle = d.cyclePeriod(now)
+	)
+
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	var changed bool
+	for _, peer := range usage.Peers() {
+		// The unmanaged peers have no names of their own, so their traffic isn't accounted.
+		if peer.Unmanaged {
+			continue
+		}
+
+		current := transfer{
+			Rx: peer.TransferRxTotal,
+			Tx: peer.TransferTxTotal,
+		}
+
+		snapshot, found := d.snapshotPeerAccessor[peer.Name]
+		if !found {
+			// The traffic before the peer got into the ledger is unknown, so it's just a baseline.
+			d.snapshotPeerAccessor[peer.Name] = snapshotPeer{
+				Last:  current,
+				Daily: make(map[string]transfer),
+				Cycle: make(map[string]transfer),
+			}
+			changed = true
+
+			continue
+		}
+
+		if current == snapshot.Last {
+			continue
+		}
+
+		delta := transfer{
+			Rx: diffTotal(current.Rx, snapshot.Last.Rx),
+			Tx: diffTotal(current.Tx, snapshot.Last.Tx),
+		}
+
+		if snapshot.Daily == nil {
+			snapshot.Daily = make(map[string]transfer)
+		}
+		if snapshot.Cycle == nil {
+			snapshot.Cycle = make(map[string]transfer)
+		}
+
+		snapshot.Daily[day.Key] = snapshot.Daily[day.Key].Add(delta)
+		snapshot.Cycle[cycle.Key] = snapshot.Cycle[cycle.Key].Add(delta)
+		snapshot.Last = current
+		d.pruneDaily(snapshot.Daily, now)
+
+		d.snapshotPeerAccessor[peer.Name] = snapshot
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+
+	if err = d.saveSnapshotPeerAccessor(d.snapshotPeerAccessor); err != nil {
+		return fmt.Errorf("can't flush ledger: %w", err)
+	}
+
+	return nil
+}
+
+func (d *Domain) pruneDaily(daily map[string]transfer, now time.Time) {
+	threshold := d.dayPeriod(now.AddDate(0, 0, -d.cfg.DailyRetentionDays)).Key
+	for key := range daily {
+		// The keys are ISO dates, so they are ordered lexicographically.
+		if key < threshold {
+			delete(daily, key)
+		}
+	}
+}
+
+// parsePeriod supports the current billing cycle (empty or "month"), "today", "yesterday",
+// a day as YYYY-MM-DD and a billing cycle started at the month as YYYY-MM.
+func (d *Domain) parsePeriod(raw string, now time.Time) (*period, error) {
+	switch raw {
+	case "", periodCycle:
+		p := d.cyclePeriod(now)
+		return &p, nil
+	case periodToday:
+		p := d.dayPeriod(now)
+		return &p, nil
+	case periodYesterday:
+		p := d.dayPeriod(now.AddDate(0, 0, -1))
+		return &p, nil
+	}
+
+	if at, err := time.ParseInLocation(dayKeyLayout, raw, d.cfg.Location); err == nil {
+		p := d.dayPeriod(at)
+		return &p, nil
+	}
+
+	if at, err := time.ParseInLocation(cycleKeyLayout, raw, d.cfg.Location); err == nil {
+		p := d.cyclePeriod(at.AddDate(0, 0, d.cfg.BillingCycleStartDay-1))
+		return &p, nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrInvalidPeriod, raw)
+}
+
+func (d *Domain) dayPeriod(at time.Time) period {
+	at = at.In(d.cfg.Location)
+	from := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, d.cfg.Location)
+
+	return period{
+		Key:   from.Format(dayKeyLayout),
+		Daily: true,
+		From:  from,
+		To:    from.AddDate(0, 0, 1),
+	}
+}
+
+// cyclePeriod returns the billing cycle containing the moment, the cycle is keyed by the month it starts at.
+func (d *Domain) cyclePeriod(at time.Time) period {
+	at = at.In(d.cfg.Location)
+	from := time.Date(at.Year(), at.Month(), d.cfg.BillingCycleStartDay, 0, 0, 0, 0, d.cfg.Location)
+	if at.Day() < d.cfg.BillingCycleStartDay {
+		from = from.AddDate(0, -1, 0)
+	}
+
+	return period{
+		Key:  from.Format(cycleKeyLayout),
+		From: from,
+		To:   from.AddDate(0, 1, 0),
+	}
+}
+
+func diffTotal(current, last int64) int64 {
+	// The totals never decrease unless the wgwatcher snapshot is lost, so it's a new baseline.
+	if current < last {
+		return 0
+	}
+
+	return current - last
+}
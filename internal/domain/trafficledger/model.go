@@ -0,0 +1,24 @@
+package trafficledger
+
+import "time"
+
+type Traffic struct {
+	Peer       string
+	Period     string
+	From       time.Time
+	To         time.Time
+	TransferRx int64
+	TransferTx int64
+}
+
+type transfer struct {
+	Rx int64 `json:"rx,omitempty"`
+	Tx int64 `json:"tx,omitempty"`
+}
+
+func (t transfer) Add(other transfer) transfer {
+	return transfer{
+		Rx: t.Rx + other.Rx,
+		Tx: t.Tx + other.Tx,
+	}
+}
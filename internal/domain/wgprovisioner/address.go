@@ -0,0 +1,108 @@
+package wgprovisioner
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path"
+	"strings"
+
+	"gopkg.in/ini.v1"
+
+	"github.com/whiteforestz/iino/internal/domain/wgwatcher"
+)
+
+// allocateAddress returns the first free address of the subnet. The address is taken if it's routed
+// to any peer of the interface or set in any client conf, the confs of the removed peers count too.
+func (d *Domain) allocateAddress(ifaceConf *wgwatcher.InterfaceConf, ifaceUsage *wgwatcher.InterfaceUsage) (string, error) {
+	taken, err := getConfAddresses(ifaceConf)
+	if err != nil {
+		return "", fmt.Errorf("can't get conf addresses: %w", err)
+	}
+
+	for _, peer := range ifaceUsage.Peer {
+		for _, allowedIP := range peer.AllowedIPs {
+			if ip := parseHostAddress(allowedIP); ip != nil {
+				taken[ip.String()] = struct{}{}
+			}
+		}
+	}
+
+	var (
+		network   = binary.BigEndian.Uint32(d.cfg.SubnetNet.IP.To4())
+		ones, _   = d.cfg.SubnetNet.Mask.Size()
+		broadcast = network | (1<<(32-ones) - 1)
+	)
+
+	// The network address and the first host, which is left for the server, are skipped.
+	for v := network + 2; v < broadcast; v++ {
+		ip := make(net.IP, net.IPv4len)
+		binary.BigEndian.PutUint32(ip, v)
+
+		if _, found := taken[ip.String()]; !found {
+			return ip.String(), nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrSubnetExhausted, d.cfg.SubnetNet)
+}
+
+func getConfAddresses(ifaceConf *wgwatcher.InterfaceConf) (map[string]struct{}, error) {
+	taken := make(map[string]struct{})
+
+	err := fs.WalkDir(os.DirFS(ifaceConf.ConfDirPath), ".", func(ep string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("fs error at %q: %w", ep, err)
+		}
+
+		if e.IsDir() || !ifaceConf.ConfPatternRe.MatchString(e.Name()) {
+			return nil
+		}
+
+		var conf iniConf
+		if err = ini.MapTo(&conf, path.Join(ifaceConf.ConfDirPath, ep)); err != nil {
+			return fmt.Errorf("can't map conf at %q: %w", ep, err)
+		}
+
+		for _, address := range strings.Split(conf.Interface.Address, ",") {
+			if ip := parseHostAddress(address); ip != nil {
+				taken[ip.String()] = struct{}{}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't walk dir: %w", err)
+	}
+
+	return taken, nil
+}
+
+// parseHostAddress returns the IPv4 address of the host, e.g. 10.0.0.2/32 or 10.0.0.2, the networks are ignored.
+func parseHostAddress(raw string) net.IP {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+
+	if !strings.Contains(raw, "/") {
+		return net.ParseIP(raw).To4()
+	}
+
+	ip, ipNet, err := net.ParseCIDR(raw)
+	if err != nil {
+		return nil
+	}
+
+	// The address of the client conf is usually written with the mask of the whole subnet.
+	if ipNet.IP.Equal(ip) {
+		if ones, bits := ipNet.Mask.Size(); ones != bits {
+			return nil
+		}
+	}
+
+	return ip.To4()
+}
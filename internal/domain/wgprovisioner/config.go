@@ -0,0 +1,53 @@
+package wgprovisioner
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+type Config struct {
+	Interface string `split_words:"true" default:"wg0"`
+	// Subnet is where the addresses of the new peers are allocated, the first host is left for the server.
+	Subnet string `split_words:"true"`
+	// Endpoint is the address of the server the clients connect to, e.g. vpn.example.com:51820.
+	Endpoint            string `split_words:"true"`
+	DNS                 string `split_words:"true"`
+	AllowedIPs          string `split_words:"true" default:"0.0.0.0/0, ::/0"`
+	PersistentKeepalive int    `split_words:"true" default:"25"`
+	// ConfNameTemplate is the name of the client conf with %s for the peer name, it has to match the conf pattern
+	// of the interface, so the watcher finds the peer.
+	ConfNameTemplate string `split_words:"true" default:"wg0-client-%s.conf"`
+	// ServerConfPath, if set, gets the new peers too, so they survive the restart of the interface.
+	ServerConfPath string `split_words:"true"`
+	// ArchiveDirPath is where the confs of the revoked peers are moved, it must be out of the conf dir,
+	// otherwise the revoked peers are still recognized.
+	ArchiveDirPath string `split_words:"true"`
+
+	SubnetNet *net.IPNet
+}
+
+func MustNewConfig() Config {
+	var cfg Config
+	envconfig.MustProcess("provision", &cfg)
+
+	if cfg.Subnet != "" {
+		_, subnet, err := net.ParseCIDR(cfg.Subnet)
+		if err != nil {
+			panic(fmt.Sprintf("invalid subnet: %s", err))
+		}
+
+		if subnet.IP.To4() == nil {
+			panic(fmt.Sprintf("unsupported subnet %q, it has to be IPv4", cfg.Subnet))
+		}
+
+		cfg.SubnetNet = subnet
+	}
+
+	if cfg.PersistentKeepalive < 0 || cfg.PersistentKeepalive > 65535 {
+		panic(fmt.Sprintf("invalid persistent keepalive: %d", cfg.PersistentKeepalive))
+	}
+
+	return cfg
+}
@@ -0,0 +1,21 @@
+package wgprovisioner
+
+import (
+	"context"
+
+	"github.com/whiteforestz/iino/internal/domain/wgwatcher"
+)
+
+type PersistorDomain interface {
+	Save(tag string, b []byte) error
+	Load(tag string) ([]byte, error)
+}
+
+type WGWatcherDomain interface {
+	GetUsage() (*wgwatcher.Usage, error)
+	GetInterfaceConf(name string) (*wgwatcher.InterfaceConf, error)
+	GetInterfaceConfs() []wgwatcher.InterfaceConf
+	RemovePeer(ctx context.Context, name string) (*wgwatcher.PeerRef, error)
+	AddPeer(ctx context.Context, ref wgwatcher.PeerRef) error
+	GetConfPeerRef(name string) (*wgwatcher.PeerRef, error)
+}
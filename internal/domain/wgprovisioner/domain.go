@@ -0,0 +1,94 @@
+package wgprovisioner
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/whiteforestz/iino/internal/pkg/infloop"
+	"github.com/whiteforestz/iino/internal/pkg/logger"
+)
+
+const (
+	tickerPeriod = 10 * time.Second
+)
+
+type Domain struct {
+	started   chan struct{}
+	finished  chan struct{}
+	cfg       Config
+	persistor PersistorDomain
+	wgWatcher WGWatcherDomain
+
+	prepared                   bool
+	mux                        *sync.Mutex
+	snapshotSuspensionAccessor map[string]snapshotSuspension
+}
+
+func New(
+	cfg Config,
+	persistorDomain PersistorDomain,
+	wgWatcherDomain WGWatcherDomain,
+) *Domain {
+	return &Domain{
+		started:   make(chan struct{}),
+		finished:  make(chan struct{}),
+		cfg:       cfg,
+		persistor: persistorDomain,
+		wgWatcher: wgWatcherDomain,
+
+		mux: &sync.Mutex{},
+	}
+}
+
+func (d *Domain) Prepare() error {
+	var err error
+
+	d.snapshotSuspensionAccessor, err = d.loadSnapshotSuspensionAccessor()
+	if err != nil {
+		return fmt.Errorf("can't load snapshot suspension accessor: %w", err)
+	}
+
+	d.prepared = true
+
+	return nil
+}
+
+func (d *Domain) Listen(ctx context.Context) {
+	d.guard()
+
+	go d.loop(ctx)
+	<-d.started
+}
+
+func (d *Domain) Wait() {
+	<-d.finished
+}
+
+func (d *Domain) loop(ctx context.Context) {
+	ticker := time.NewTicker(tickerPeriod)
+	defer ticker.Stop()
+
+	infloop.InfLoop(ctx, ticker, infloop.Caller{
+		OnStart: func(_ context.Context) {
+			close(d.started)
+		},
+		OnFinish: func(_ context.Context) {
+			close(d.finished)
+		},
+		OnTick: func(ctx context.Context) {
+			if err := d.updateSuspensions(ctx); err != nil {
+				logger.Instance().Error("can't update suspensions", zap.Error(err))
+			}
+		},
+	})
+}
+
+func (d *Domain) guard() {
+	if !d.prepared {
+		panic("unprepared domain")
+	}
+}
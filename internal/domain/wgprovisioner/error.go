@@ -0,0 +1,13 @@
+package wgprovisioner
+
+import "errors"
+
+var (
+	ErrDisabled        = errors.New("provisioning is disabled")
+	ErrInvalidName     = errors.New("invalid name")
+	ErrPeerExists      = errors.New("peer exists")
+	ErrInterfaceDown   = errors.New("interface is down")
+	ErrSubnetExhausted = errors.New("subnet is exhausted")
+	ErrUnknownPeer     = errors.New("unknown peer")
+	ErrNotSuspended    = errors.New("peer isn't suspended")
+)
@@ -0,0 +1,33 @@
+package wgprovisioner
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+)
+
+const (
+	presharedKeyLength = 32
+)
+
+func generateKeyPair() (*keyPair, error) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("can't generate key: %w", err)
+	}
+
+	return &keyPair{
+		PrivateKey: base64.StdEncoding.EncodeToString(key.Bytes()),
+		PublicKey:  base64.StdEncoding.EncodeToString(key.PublicKey().Bytes()),
+	}, nil
+}
+
+func generatePresharedKey() (string, error) {
+	b := make([]byte, presharedKeyLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("can't read random: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(b), nil
+}
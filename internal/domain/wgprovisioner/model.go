@@ -0,0 +1,36 @@
+package wgprovisioner
+
+import "time"
+
+type ProvisionedPeer struct {
+	Name      string
+	Interface string
+	Address   string
+	ConfName  string
+	Conf      []byte // Conf is the client conf, it holds the private key of the peer.
+}
+
+type keyPair struct {
+	PrivateKey string
+	PublicKey  string
+}
+
+type iniConf struct {
+	Interface iniInterface `ini:"Interface"`
+}
+
+type iniInterface struct {
+	Address string `ini:"Address"`
+}
+
+type Revocation struct {
+	Name             string
+	Interface        string
+	ArchivedConfPath string // ArchivedConfPath is empty if the peer has no client conf, e.g. it's unmanaged.
+}
+
+// Suspension is the peer removed from the interface for a while, the zero until means until resumed.
+type Suspension struct {
+	Name  string
+	Until time.Time
+}
@@ -0,0 +1,284 @@
+package wgprovisioner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"gopkg.in/ini.v1"
+
+	"github.com/whiteforestz/iino/internal/domain/wgwatcher"
+	"github.com/whiteforestz/iino/internal/pkg/logger"
+)
+
+const (
+	confFileMode = 0o600
+)
+
+var (
+	nameRe = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,32}$`)
+)
+
+// AddPeer generates the keys of the new peer, writes its client conf next to the others and adds it
+// to the running interface, so it's able to connect right away.
+func (d *Domain) AddPeer(ctx context.Context, name string) (*ProvisionedPeer, error) {
+	d.guard()
+
+	if d.cfg.SubnetNet == nil || d.cfg.Endpoint == "" {
+		return nil, ErrDisabled
+	}
+
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	ifaceConf, err := d.wgWatcher.GetInterfaceConf(d.cfg.Interface)
+	if err != nil {
+		return nil, fmt.Errorf("can't get interface conf: %w", err)
+	}
+
+	confName, err := d.getConfName(ifaceConf, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = d.checkNameFree(name); err != nil {
+		return nil, err
+	}
+
+	usage, err := d.wgWatcher.GetUsage()
+	if err != nil {
+		return nil, fmt.Errorf("can't get usage: %w", err)
+	}
+
+	ifaceUsage, err := d.getInterfaceUsage(usage, name)
+	if err != nil {
+		return nil, err
+	}
+
+	address, err := d.allocateAddress(ifaceConf, ifaceUsage)
+	if err != nil {
+		return nil, fmt.Errorf("can't allocate address: %w", err)
+	}
+
+	keys, err := generateKeyPair()
+	if err != nil {
+		return nil, fmt.Errorf("can't generate key pair: %w", err)
+	}
+
+	presharedKey, err := generatePresharedKey()
+	if err != nil {
+		return nil, fmt.Errorf("can't generate preshared key: %w", err)
+	}
+
+	conf := d.renderClientConf(keys.PrivateKey, ifaceUsage.PublicKey, presharedKey, address)
+
+	confPath := path.Join(ifaceConf.ConfDirPath, confName)
+	if err = writeNewFile(confPath, conf); err != nil {
+		return nil, fmt.Errorf("can't write conf: %w", err)
+	}
+
+	// The watcher reads the preshared key from the conf written above.
+	ref := wgwatcher.PeerRef{
+		Name:                name,
+		Interface:           d.cfg.Interface,
+		PublicKey:           keys.PublicKey,
+		AllowedIPs:          address + "/32",
+		PersistentKeepalive: "off",
+	}
+	if err = d.wgWatcher.AddPeer(ctx, ref); err != nil {
+		if rmErr := os.Remove(confPath); rmErr != nil {
+			logger.Instance().Error("can't remove conf", zap.String("path", confPath), zap.Error(rmErr))
+		}
+
+		return nil, fmt.Errorf("can't add peer: %w", err)
+	}
+
+	if d.cfg.ServerConfPath != "" {
+		if err = d.appendServerConf(ref, presharedKey); err != nil {
+			// The peer is up already, so the failure is reported only, it's lost on the restart otherwise.
+			logger.Instance().Error("can't append server conf", zap.String("name", name), zap.Error(err))
+		}
+	}
+
+	logger.Instance().Info(
+		"peer provisioned",
+		zap.String("name", name),
+		zap.String("interface", d.cfg.Interface),
+		zap.String("address", address),
+	)
+
+	return &ProvisionedPeer{
+		Name:      name,
+		Interface: d.cfg.Interface,
+		Address:   address,
+		ConfName:  confName,
+		Conf:      conf,
+	}, nil
+}
+
+// GetPeerConf returns the client conf of the peer from any watched interface, so the conf is sent again
+// if the admin has missed it on the provisioning.
+func (d *Domain) GetPeerConf(name string) (*ProvisionedPeer, error) {
+	d.guard()
+
+	for _, ifaceConf := range d.wgWatcher.GetInterfaceConfs() {
+		confName, err := findConfName(&ifaceConf, name)
+		if err != nil {
+			return nil, fmt.Errorf("can't find conf at %q: %w", ifaceConf.Name, err)
+		}
+
+		if confName == "" {
+			continue
+		}
+
+		confPath := path.Join(ifaceConf.ConfDirPath, confName)
+
+		conf, err := os.ReadFile(confPath)
+		if err != nil {
+			return nil, fmt.Errorf("can't read conf: %w", err)
+		}
+
+		var parsed iniConf
+		if err = ini.MapTo(&parsed, conf); err != nil {
+			return nil, fmt.Errorf("can't map conf at %q: %w", confPath, err)
+		}
+
+		address, _, _ := strings.Cut(parsed.Interface.Address, "/")
+
+		return &ProvisionedPeer{
+			Name:      name,
+			Interface: ifaceConf.Name,
+			Address:   strings.TrimSpace(address),
+			ConfName:  path.Base(confName),
+			Conf:      conf,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrUnknownPeer, name)
+}
+
+// getConfName returns the name of the client conf, it has to be matched by the pattern of the interface
+// and resolve to the same name, otherwise the watcher won't recognize the peer.
+func (d *Domain) getConfName(ifaceConf *wgwatcher.InterfaceConf, name string) (string, error) {
+	if !nameRe.MatchString(name) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+
+	confName := fmt.Sprintf(d.cfg.ConfNameTemplate, name)
+
+	match := ifaceConf.ConfPatternRe.FindStringSubmatch(confName)
+	if len(match) != 2 || match[1] != name {
+		return "", fmt.Errorf("%w: %q isn't matched by the conf pattern", ErrInvalidName, confName)
+	}
+
+	return confName, nil
+}
+
+// checkNameFree looks for the conf of the name at every watched interface, the watcher refuses
+// the names duplicated across the interfaces, even of the peers missed on the interfaces.
+func (d *Domain) checkNameFree(name string) error {
+	for _, ifaceConf := range d.wgWatcher.GetInterfaceConfs() {
+		confName, err := findConfName(&ifaceConf, name)
+		if err != nil {
+			return fmt.Errorf("can't find conf at %q: %w", ifaceConf.Name, err)
+		}
+
+		if confName != "" {
+			return fmt.Errorf("%w: %q", ErrPeerExists, name)
+		}
+	}
+
+	return nil
+}
+
+func (d *Domain) getInterfaceUsage(usage *wgwatcher.Usage, name string) (*wgwatcher.InterfaceUsage, error) {
+	for _, peer := range usage.Peers() {
+		if peer.Name == name {
+			return nil, fmt.Errorf("%w: %q", ErrPeerExists, name)
+		}
+	}
+
+	for i := range usage.Interface {
+		ifaceUsage := &usage.Interface[i]
+		if ifaceUsage.Name != d.cfg.Interface {
+			continue
+		}
+
+		if !ifaceUsage.Up || ifaceUsage.PublicKey == "" {
+			return nil, fmt.Errorf("%w: %q", ErrInterfaceDown, d.cfg.Interface)
+		}
+
+		return ifaceUsage, nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrInterfaceDown, d.cfg.Interface)
+}
+
+func (d *Domain) renderClientConf(privateKey, serverPublicKey, presharedKey, address string) []byte {
+	var b strings.Builder
+
+	b.WriteString("[Interface]\n")
+	b.WriteString(fmt.Sprintf("PrivateKey = %s\n", privateKey))
+	b.WriteString(fmt.Sprintf("Address = %s/32\n", address))
+	if d.cfg.DNS != "" {
+		b.WriteString(fmt.Sprintf("DNS = %s\n", d.cfg.DNS))
+	}
+
+	b.WriteString("\n[Peer]\n")
+	b.WriteString(fmt.Sprintf("PublicKey = %s\n", serverPublicKey))
+	b.WriteString(fmt.Sprintf("PresharedKey = %s\n", presharedKey))
+	b.WriteString(fmt.Sprintf("Endpoint = %s\n", d.cfg.Endpoint))
+	b.WriteString(fmt.Sprintf("AllowedIPs = %s\n", d.cfg.AllowedIPs))
+	if d.cfg.PersistentKeepalive > 0 {
+		b.WriteString(fmt.Sprintf("PersistentKeepalive = %d\n", d.cfg.PersistentKeepalive))
+	}
+
+	return []byte(b.String())
+}
+
+func (d *Domain) appendServerConf(ref wgwatcher.PeerRef, presharedKey string) error {
+	f, err := os.OpenFile(d.cfg.ServerConfPath, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return fmt.Errorf("can't open: %w", err)
+	}
+	defer f.Close()
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("\n# %s\n", ref.Name))
+	b.WriteString("[Peer]\n")
+	b.WriteString(fmt.Sprintf("PublicKey = %s\n", ref.PublicKey))
+	b.WriteString(fmt.Sprintf("PresharedKey = %s\n", presharedKey))
+	b.WriteString(fmt.Sprintf("AllowedIPs = %s\n", ref.AllowedIPs))
+
+	if _, err = f.WriteString(b.String()); err != nil {
+		return fmt.Errorf("can't write: %w", err)
+	}
+
+	return nil
+}
+
+// writeNewFile writes the file only if it doesn't exist, so the conf of another peer is never overwritten.
+func writeNewFile(p string, b []byte) error {
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_EXCL, confFileMode)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("%w: %q", ErrPeerExists, path.Base(p))
+		}
+
+		return fmt.Errorf("can't open: %w", err)
+	}
+
+	if _, err = f.Write(b); err != nil {
+		_ = f.Close()
+		_ = os.Remove(p)
+
+		return fmt.Errorf("can't write: %w", err)
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,264 @@
+package wgprovisioner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/whiteforestz/iino/internal/domain/wgwatcher"
+	"github.com/whiteforestz/iino/internal/pkg/logger"
+)
+
+const (
+	archiveDirMode = 0o700
+
+	serverConfKeyPublicKey = "PublicKey"
+)
+
+// Revoke removes the peer from the running interface for good, its client conf is archived,
+// so the peer isn't recognized anymore and its address may be given to another peer.
+func (d *Domain) Revoke(ctx context.Context, name string) (*Revocation, error) {
+	d.guard()
+
+	if d.cfg.ArchiveDirPath == "" {
+		return nil, fmt.Errorf("%w: archive dir path isn't set", ErrDisabled)
+	}
+
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	var ref *wgwatcher.PeerRef
+	if snapshot, found := d.snapshotSuspensionAccessor[name]; found {
+		ref = &snapshot.Peer
+	} else {
+		var err error
+
+		ref, err = d.wgWatcher.RemovePeer(ctx, name)
+		if err != nil && !errors.Is(err, wgwatcher.ErrUnknownPeer) {
+			return nil, fmt.Errorf("can't remove peer: %w", err)
+		}
+	}
+
+	// The peer missed on the interface, e.g. disabled by the quota or of the interface down,
+	// is known by its conf only.
+	if ref == nil {
+		var err error
+
+		ref, err = d.wgWatcher.GetConfPeerRef(name)
+		if err != nil && !errors.Is(err, wgwatcher.ErrUnknownPeer) {
+			return nil, fmt.Errorf("can't get conf peer: %w", err)
+		}
+	}
+
+	iface := d.cfg.Interface
+	if ref != nil && ref.Interface != "" {
+		iface = ref.Interface
+	}
+
+	ifaceConf, err := d.wgWatcher.GetInterfaceConf(iface)
+	if err != nil {
+		return nil, fmt.Errorf("can't get interface conf: %w", err)
+	}
+
+	// The peer missed on the interface may still have the conf left by the failed revocation.
+	confName, err := findConfName(ifaceConf, name)
+	if err != nil {
+		return nil, fmt.Errorf("can't find conf: %w", err)
+	}
+
+	if ref == nil && confName == "" {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownPeer, name)
+	}
+
+	// The peer is removed from the server conf before the conf is archived, so the failed revocation
+	// is retried as a whole, the peer would connect again on the restart of the interface otherwise.
+	if d.cfg.ServerConfPath != "" {
+		if ref == nil || ref.PublicKey == "" {
+			return nil, fmt.Errorf("can't remove peer from server conf: public key of %q is unknown", name)
+		}
+
+		if err = removeServerConfPeer(d.cfg.ServerConfPath, ref.PublicKey); err != nil {
+			return nil, fmt.Errorf("can't remove peer from server conf: %w", err)
+		}
+	}
+
+	revocation := Revocation{
+		Name:      name,
+		Interface: iface,
+	}
+
+	if confName != "" {
+		revocation.ArchivedConfPath, err = d.archiveConf(path.Join(ifaceConf.ConfDirPath, confName))
+		if err != nil {
+			return nil, fmt.Errorf("can't archive conf: %w", err)
+		}
+	}
+
+	if _, found := d.snapshotSuspensionAccessor[name]; found {
+		delete(d.snapshotSuspensionAccessor, name)
+
+		if err = d.saveSnapshotSuspensionAccessor(d.snapshotSuspensionAccessor); err != nil {
+			return nil, fmt.Errorf("can't flush suspensions: %w", err)
+		}
+	}
+
+	logger.Instance().Info(
+		"peer revoked",
+		zap.String("name", name),
+		zap.String("interface", iface),
+		zap.String("archivedConfPath", revocation.ArchivedConfPath),
+	)
+
+	return &revocation, nil
+}
+
+// archiveConf moves the conf to the archive dir, the name gets the time of the revocation,
+// so the same name may be revoked many times.
+func (d *Domain) archiveConf(confPath string) (string, error) {
+	if err := os.MkdirAll(d.cfg.ArchiveDirPath, archiveDirMode); err != nil {
+		return "", fmt.Errorf("can't create archive dir: %w", err)
+	}
+
+	archivedConfPath := path.Join(
+		d.cfg.ArchiveDirPath,
+		fmt.Sprintf("%s.%d", path.Base(confPath), time.Now().Unix()),
+	)
+
+	if err := os.Rename(confPath, archivedConfPath); err == nil {
+		return archivedConfPath, nil
+	}
+
+	// The archive dir may be on another device, so the conf is copied then.
+	b, err := os.ReadFile(confPath)
+	if err != nil {
+		return "", fmt.Errorf("can't read conf: %w", err)
+	}
+
+	if err = writeNewFile(archivedConfPath, b); err != nil {
+		return "", fmt.Errorf("can't write archived conf: %w", err)
+	}
+
+	if err = os.Remove(confPath); err != nil {
+		return "", fmt.Errorf("can't remove conf: %w", err)
+	}
+
+	return archivedConfPath, nil
+}
+
+// findConfName returns the name of the client conf of the peer, it's empty if there is no conf.
+func findConfName(ifaceConf *wgwatcher.InterfaceConf, name string) (string, error) {
+	var confName string
+
+	err := fs.WalkDir(os.DirFS(ifaceConf.ConfDirPath), ".", func(ep string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("fs error at %q: %w", ep, err)
+		}
+
+		if e.IsDir() {
+			return nil
+		}
+
+		match := ifaceConf.ConfPatternRe.FindStringSubmatch(e.Name())
+		if len(match) == 2 && match[1] == name {
+			confName = ep
+
+			return fs.SkipAll
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("can't walk dir: %w", err)
+	}
+
+	return confName, nil
+}
+
+// removeServerConfPeer drops the [Peer] section with the public key along with the comments above it.
+func removeServerConfPeer(p, publicKey string) error {
+	raw, err := os.ReadFile(p)
+	if err != nil {
+		return fmt.Errorf("can't read: %w", err)
+	}
+
+	var (
+		result  bytes.Buffer
+		section bytes.Buffer
+		pending bytes.Buffer
+		skip    bool
+		found   bool
+	)
+
+	flush := func() {
+		if !skip {
+			_, _ = io.Copy(&result, &section)
+		}
+
+		section.Reset()
+		skip = false
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	for scanner.Scan() {
+		var (
+			line    = scanner.Text()
+			trimmed = strings.TrimSpace(line)
+		)
+
+		switch {
+		case strings.HasPrefix(trimmed, "["):
+			flush()
+			_, _ = io.Copy(&section, &pending)
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+			// The blank lines and comments belong to the next section unless the current one goes on.
+			pending.WriteString(line + "\n")
+			continue
+		default:
+			_, _ = io.Copy(&section, &pending)
+
+			if key, value, ok := strings.Cut(trimmed, "="); ok &&
+				strings.TrimSpace(key) == serverConfKeyPublicKey && strings.TrimSpace(value) == publicKey {
+				skip, found = true, true
+			}
+		}
+
+		section.WriteString(line + "\n")
+	}
+	if err = scanner.Err(); err != nil {
+		return fmt.Errorf("can't scan: %w", err)
+	}
+
+	flush()
+	_, _ = io.Copy(&result, &pending)
+
+	if !found {
+		return nil
+	}
+
+	info, err := os.Stat(p)
+	if err != nil {
+		return fmt.Errorf("can't stat: %w", err)
+	}
+
+	// The conf is replaced at once, so the interface never sees the half written one.
+	tmp := p + ".tmp"
+	if err = os.WriteFile(tmp, result.Bytes(), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("can't write: %w", err)
+	}
+
+	if err = os.Rename(tmp, p); err != nil {
+		return fmt.Errorf("can't replace: %w", err)
+	}
+
+	return nil
+}
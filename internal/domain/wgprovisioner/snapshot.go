@@ -0,0 +1,79 @@
+package wgprovisioner
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/whiteforestz/iino/internal/domain/persistor"
+	"github.com/whiteforestz/iino/internal/domain/wgwatcher"
+)
+
+const (
+	tagSuspension = "suspension"
+)
+
+// snapshotSuspension keeps the ref under the key of the former spec, so the older snapshots are still read.
+type snapshotSuspension struct {
+	Peer      wgwatcher.PeerRef `json:"spec"`
+	UntilUnix int64             `json:"untilUnix,omitempty"`
+}
+
+func (d *Domain) saveSnapshotSuspensionAccessor(accessor map[string]snapshotSuspension) error {
+	b, err := castSnapshotSuspensionAccessorToBinary(accessor)
+	if err != nil {
+		return fmt.Errorf("can't cast accessor: %w", err)
+	}
+
+	if err = d.persistor.Save(tagSuspension, b); err != nil {
+		return fmt.Errorf("can't save: %w", err)
+	}
+
+	return nil
+}
+
+func (d *Domain) loadSnapshotSuspensionAccessor() (map[string]snapshotSuspension, error) {
+	hash, err := d.persistor.Load(tagSuspension)
+	if err != nil {
+		if errors.Is(err, persistor.ErrNotExists) {
+			return make(map[string]snapshotSuspension), nil
+		}
+
+		return nil, fmt.Errorf("can't load persited data: %w", err)
+	}
+
+	accessor, err := castSnapshotSuspensionAccessorFromBinary(hash)
+	if err != nil {
+		return nil, fmt.Errorf("can't cast accessor: %w", err)
+	}
+
+	return accessor, nil
+}
+
+func castSnapshotSuspensionAccessorToBinary(accessor map[string]snapshotSuspension) ([]byte, error) {
+	b, err := json.Marshal(&accessor)
+	if err != nil {
+		return nil, fmt.Errorf("can't marshal: %w", err)
+	}
+
+	return []byte(base64.StdEncoding.EncodeToString(b)), nil
+}
+
+func castSnapshotSuspensionAccessorFromBinary(hash []byte) (map[string]snapshotSuspension, error) {
+	b, err := base64.StdEncoding.DecodeString(string(hash))
+	if err != nil {
+		return nil, fmt.Errorf("can't decode base64: %w", err)
+	}
+
+	var accessor map[string]snapshotSuspension
+	if err := json.Unmarshal(b, &accessor); err != nil {
+		return nil, fmt.Errorf("can't unmarshal: %w", err)
+	}
+
+	if accessor == nil {
+		accessor = make(map[string]snapshotSuspension)
+	}
+
+	return accessor, nil
+}
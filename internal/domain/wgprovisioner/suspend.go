@@ -0,0 +1,207 @@
+package wgprovisioner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/whiteforestz/iino/internal/domain/wgwatcher"
+	"github.com/whiteforestz/iino/internal/pkg/logger"
+)
+
+// Suspend removes the peer from the running interface, the zero duration suspends it until resumed.
+// Suspending the suspended peer changes the duration only. The peer missed on the interface,
+// e.g. disabled by the quota, is suspended by its conf, so it isn't added back by anyone else.
+func (d *Domain) Suspend(ctx context.Context, name string, duration time.Duration) (*Suspension, error) {
+	d.guard()
+
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	var (
+		snapshot, found = d.snapshotSuspensionAccessor[name]
+		removed         bool
+	)
+	if !found {
+		ref, err := d.wgWatcher.RemovePeer(ctx, name)
+		switch {
+		case errors.Is(err, wgwatcher.ErrUnknownPeer):
+			ref, err = d.getConfPeerRef(name)
+			if err != nil {
+				return nil, err
+			}
+		case err != nil:
+			return nil, fmt.Errorf("can't remove peer: %w", err)
+		default:
+			removed = true
+		}
+
+		snapshot.Peer = *ref
+	}
+
+	snapshot.UntilUnix = 0
+	if duration > 0 {
+		snapshot.UntilUnix = time.Now().Add(duration).Unix()
+	}
+
+	d.snapshotSuspensionAccessor[name] = snapshot
+
+	if err := d.saveSnapshotSuspensionAccessor(d.snapshotSuspensionAccessor); err != nil {
+		// The suspension would be lost on the restart, so the peer is rather added back.
+		if !found {
+			delete(d.snapshotSuspensionAccessor, name)
+		}
+
+		if removed {
+			if addErr := d.wgWatcher.AddPeer(ctx, snapshot.Peer); addErr != nil {
+				logger.Instance().Error("can't add peer back", zap.String("name", name), zap.Error(addErr))
+			}
+		}
+
+		return nil, fmt.Errorf("can't flush suspensions: %w", err)
+	}
+
+	logger.Instance().Info("peer suspended", zap.String("name", name), zap.Int64("untilUnix", snapshot.UntilUnix))
+
+	return castSuspension(name, snapshot), nil
+}
+
+// Resume adds the suspended peer back to the running interface.
+func (d *Domain) Resume(ctx context.Context, name string) error {
+	d.guard()
+
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	if _, found := d.snapshotSuspensionAccessor[name]; !found {
+		return fmt.Errorf("%w: %q", ErrNotSuspended, name)
+	}
+
+	if err := d.resume(ctx, name); err != nil {
+		return err
+	}
+
+	if err := d.saveSnapshotSuspensionAccessor(d.snapshotSuspensionAccessor); err != nil {
+		return fmt.Errorf("can't flush suspensions: %w", err)
+	}
+
+	return nil
+}
+
+// IsSuspended reports whether the peer is suspended, so the others don't add it back.
+func (d *Domain) IsSuspended(name string) bool {
+	d.guard()
+
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	_, found := d.snapshotSuspensionAccessor[name]
+
+	return found
+}
+
+// GetSuspensions returns the suspended peers sorted by name.
+func (d *Domain) GetSuspensions() []Suspension {
+	d.guard()
+
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	suspensions := make([]Suspension, 0, len(d.snapshotSuspensionAccessor))
+	for name, snapshot := range d.snapshotSuspensionAccessor {
+		suspensions = append(suspensions, *castSuspension(name, snapshot))
+	}
+
+	sort.Slice(suspensions, func(i, j int) bool {
+		return suspensions[i].Name < suspensions[j].Name
+	})
+
+	return suspensions
+}
+
+// updateSuspensions lifts the expired suspensions and removes the suspended peers again
+// if the interface has been restarted with them.
+func (d *Domain) updateSuspensions(ctx context.Context) error {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	var (
+		nowUnix = time.Now().Unix()
+		changed bool
+	)
+
+	for name, snapshot := range d.snapshotSuspensionAccessor {
+		if snapshot.UntilUnix != 0 && snapshot.UntilUnix <= nowUnix {
+			if err := d.resume(ctx, name); err != nil {
+				logger.Instance().Error("can't lift suspension", zap.String("name", name), zap.Error(err))
+				continue
+			}
+
+			changed = true
+
+			continue
+		}
+
+		_, err := d.wgWatcher.RemovePeer(ctx, name)
+		if err != nil && !errors.Is(err, wgwatcher.ErrUnknownPeer) {
+			logger.Instance().Error("can't remove suspended peer", zap.String("name", name), zap.Error(err))
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+
+	if err := d.saveSnapshotSuspensionAccessor(d.snapshotSuspensionAccessor); err != nil {
+		return fmt.Errorf("can't flush suspensions: %w", err)
+	}
+
+	return nil
+}
+
+func (d *Domain) resume(ctx context.Context, name string) error {
+	if err := d.wgWatcher.AddPeer(ctx, d.snapshotSuspensionAccessor[name].Peer); err != nil {
+		return fmt.Errorf("can't add peer: %w", err)
+	}
+
+	delete(d.snapshotSuspensionAccessor, name)
+
+	logger.Instance().Info("peer resumed", zap.String("name", name))
+
+	return nil
+}
+
+// getConfPeerRef returns the ref of the peer missed on the interface, the peer without keys in the conf
+// can't be added back, so it isn't suspended.
+func (d *Domain) getConfPeerRef(name string) (*wgwatcher.PeerRef, error) {
+	ref, err := d.wgWatcher.GetConfPeerRef(name)
+	if err != nil {
+		if errors.Is(err, wgwatcher.ErrUnknownPeer) {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownPeer, name)
+		}
+
+		return nil, fmt.Errorf("can't get conf peer: %w", err)
+	}
+
+	if ref.PublicKey == "" {
+		return nil, fmt.Errorf("%w: conf of %q has no keys", ErrUnknownPeer, name)
+	}
+
+	return ref, nil
+}
+
+func castSuspension(name string, snapshot snapshotSuspension) *Suspension {
+	suspension := Suspension{
+		Name: name,
+	}
+
+	if snapshot.UntilUnix != 0 {
+		suspension.Until = time.Unix(snapshot.UntilUnix, 0)
+	}
+
+	return &suspension
+}
@@ -0,0 +1,48 @@
+package wgwatcher
+
+import (
+	"time"
+)
+
+const (
+	// OnlineThreshold is how long the peer stays online after the latest handshake, WireGuard handshakes
+	// every 2 minutes while there is any traffic.
+	OnlineThreshold = 2 * time.Minute
+)
+
+// IsOnline reports whether the handshake is recent enough for the peer to be online.
+func IsOnline(nowUnix, latestHandshakeUnix int64) bool {
+	return (nowUnix - latestHandshakeUnix) < int64(OnlineThreshold.Seconds())
+}
+
+// publishPeerStateChanges compares the peers with the previous update, the first update sets the initial state only,
+// so the restart doesn't report every peer. The peers gone from the interface are forgotten silently.
+func (d *Domain) publishPeerStateChanges(peers []Peer, now time.Time) {
+	var (
+		nowUnix            = now.Unix()
+		peerOnlineAccessor = make(map[string]bool, len(peers))
+	)
+
+	for _, peer := range peers {
+		var (
+			key    = trackKey(peer)
+			online = IsOnline(nowUnix, peer.LatestHandshakeUnix)
+		)
+		peerOnlineAccessor[key] = online
+
+		wasOnline, found := d.peerOnlineAccessor[key]
+		if !found || wasOnline == online {
+			continue
+		}
+
+		d.events.Publish(PeerStateChanged{
+			Name:      peer.Name,
+			Interface: peer.Interface,
+			Online:    online,
+			Endpoint:  peer.Endpoint,
+			AtUnix:    nowUnix,
+		})
+	}
+
+	d.peerOnlineAccessor = peerOnlineAccessor
+}
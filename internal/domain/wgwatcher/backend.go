@@ -0,0 +1,53 @@
+package wgwatcher
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	backendExec    = "exec"
+	backendNetlink = "netlink"
+)
+
+// backend talks to the running WireGuard interfaces.
+type backend interface {
+	// GetDevices returns the state of the watched interfaces, the interfaces not running are missed.
+	GetDevices(ctx context.Context) ([]device, error)
+	// AddPeer adds the peer or updates the existing one.
+	AddPeer(ctx context.Context, iface string, spec PeerSpec) error
+	RemovePeer(ctx context.Context, iface, publicKey string) error
+}
+
+// device is the state of the running interface, the keys are base64 encoded.
+type device struct {
+	Name         string
+	PublicKey    string
+	ListenPort   int64
+	FirewallMark string
+	Peer         []devicePeer
+}
+
+type devicePeer struct {
+	PublicKey           string
+	PresharedKey        string // PresharedKey is empty when the peer has none.
+	Endpoint            string // Endpoint is empty until the first handshake.
+	AllowedIPs          []string
+	LatestHandshakeUnix int64
+	TransferRx          int64
+	TransferTx          int64
+	PersistentKeepalive time.Duration // PersistentKeepalive is zero when it's off.
+}
+
+func newBackend(cfg Config) backend {
+	ifaces := make([]string, 0, len(cfg.InterfaceConfs))
+	for _, ifaceConf := range cfg.InterfaceConfs {
+		ifaces = append(ifaces, ifaceConf.Name)
+	}
+
+	if cfg.Backend == backendNetlink {
+		return newNetlinkBackend(ifaces)
+	}
+
+	return newExecBackend(cfg.Cmd, cfg.CmdArgs, ifaces)
+}
@@ -0,0 +1,244 @@
+package wgwatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	dumpInterfaceTokenCount = 4
+	dumpPeerTokenCount      = 8
+
+	dumpValueNone = "(none)"
+	dumpValueOff  = "off"
+)
+
+// execBackend runs the `wg` tool and parses its dump.
+type execBackend struct {
+	cmd    string
+	args   []string
+	ifaces []string
+}
+
+func newExecBackend(cmd string, args, ifaces []string) *execBackend {
+	return &execBackend{
+		cmd:    cmd,
+		args:   args,
+		ifaces: ifaces,
+	}
+}
+
+func (b *execBackend) GetDevices(ctx context.Context) ([]device, error) {
+	raw, err := exec.CommandContext(ctx, b.cmd, b.args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("can't exec cmd: %w", err)
+	}
+
+	devices, err := parseDump(raw, b.ifaces)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse dump: %w", err)
+	}
+
+	return devices, nil
+}
+
+func (b *execBackend) AddPeer(ctx context.Context, iface string, spec PeerSpec) error {
+	var (
+		stdin string
+		args  = []string{"peer", spec.PublicKey}
+	)
+
+	if spec.AllowedIPs != "" && spec.AllowedIPs != dumpValueNone {
+		args = append(args, "allowed-ips", spec.AllowedIPs)
+	}
+
+	if spec.PersistentKeepalive != "" && spec.PersistentKeepalive != dumpValueOff {
+		args = append(args, "persistent-keepalive", spec.PersistentKeepalive)
+	}
+
+	// The preshared key is accepted as a file only, so it's passed through stdin to keep it off the disk.
+	if spec.PresharedKey != "" && spec.PresharedKey != dumpValueNone {
+		args = append(args, "preshared-key", "/dev/stdin")
+		stdin = spec.PresharedKey
+	}
+
+	return b.execSet(ctx, iface, stdin, args...)
+}
+
+func (b *execBackend) RemovePeer(ctx context.Context, iface, publicKey string) error {
+	return b.execSet(ctx, iface, "", "peer", publicKey, "remove")
+}
+
+func (b *execBackend) execSet(ctx context.Context, iface, stdin string, args ...string) error {
+	cmd := exec.CommandContext(ctx, b.cmd, append([]string{"set", iface}, args...)...)
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("can't exec cmd: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
+
+// parseDump parses the output of both `wg show all dump`, prefixing every line with the interface,
+// and `wg show INTERFACE dump`, which is allowed with a single watched interface only.
+// The interfaces not watched are skipped.
+func parseDump(raw []byte, ifaces []string) ([]device, error) {
+	watched := make(map[string]struct{}, len(ifaces))
+	for _, iface := range ifaces {
+		watched[iface] = struct{}{}
+	}
+
+	var (
+		devices   []device
+		deviceIdx = make(map[string]int)
+	)
+	for _, line := range strings.Split(string(raw), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		iface, tokens, err := extractDumpTokens(line, ifaces)
+		if err != nil {
+			return nil, fmt.Errorf("can't extract dump tokens: %w", err)
+		}
+
+		if _, found := watched[iface]; !found {
+			continue
+		}
+
+		// Interface lines go first in the dump of the interface.
+		if len(tokens) == dumpInterfaceTokenCount {
+			dev, err := extractDeviceData(tokens)
+			if err != nil {
+				return nil, fmt.Errorf("can't extract interface data: %w", err)
+			}
+
+			dev.Name = iface
+			deviceIdx[iface] = len(devices)
+			devices = append(devices, *dev)
+
+			continue
+		}
+
+		idx, found := deviceIdx[iface]
+		if !found {
+			return nil, fmt.Errorf("peer of %q goes before the interface", iface)
+		}
+
+		peer, err := extractDevicePeerData(tokens)
+		if err != nil {
+			return nil, fmt.Errorf("can't extract peer data: %w", err)
+		}
+
+		devices[idx].Peer = append(devices[idx].Peer, *peer)
+	}
+
+	return devices, nil
+}
+
+func extractDumpTokens(line string, ifaces []string) (string, []string, error) {
+	tokens := strings.FieldsFunc(line, func(r rune) bool {
+		return unicode.IsSpace(r)
+	})
+
+	switch len(tokens) {
+	case dumpInterfaceTokenCount, dumpPeerTokenCount:
+		if len(ifaces) != 1 {
+			return "", nil, errors.New("dump of a single interface while many are watched")
+		}
+
+		return ifaces[0], tokens, nil
+	case dumpInterfaceTokenCount + 1, dumpPeerTokenCount + 1:
+		return tokens[0], tokens[1:], nil
+	default:
+		return "", nil, fmt.Errorf("unexpected line content length: %d", len(tokens))
+	}
+}
+
+// extractDeviceData parses the interface line of the dump, the private key is dropped right away.
+func extractDeviceData(tokens []string) (*device, error) {
+	if len(tokens) != dumpInterfaceTokenCount {
+		return nil, fmt.Errorf("unexpected line content length: %d", len(tokens))
+	}
+
+	publicKey, rawListenPort, firewallMark := tokens[1], tokens[2], tokens[3]
+
+	listenPort, err := strconv.ParseInt(rawListenPort, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected port format: %w", err)
+	}
+
+	return &device{
+		PublicKey:    publicKey,
+		ListenPort:   listenPort,
+		FirewallMark: firewallMark,
+	}, nil
+}
+
+func extractDevicePeerData(tokens []string) (*devicePeer, error) {
+	if len(tokens) != dumpPeerTokenCount {
+		return nil, fmt.Errorf("unexpected line content length: %d", len(tokens))
+	}
+
+	publicKey, presharedKey, endpoint, rawLatestHandshake := tokens[0], tokens[1], tokens[2], tokens[4]
+	allowedIPs, persistentKeepalive := tokens[3], tokens[7]
+	rawRx, rawTx := tokens[5], tokens[6]
+
+	latestHandshakeUnix, err := strconv.ParseInt(rawLatestHandshake, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected time format: %w", err)
+	}
+
+	rx, err := strconv.ParseInt(rawRx, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected byte format: %w", err)
+	}
+
+	tx, err := strconv.ParseInt(rawTx, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected byte format: %w", err)
+	}
+
+	var keepalive time.Duration
+	if persistentKeepalive != dumpValueOff {
+		keepaliveSeconds, err := strconv.ParseInt(persistentKeepalive, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected keepalive format: %w", err)
+		}
+
+		keepalive = time.Duration(keepaliveSeconds) * time.Second
+	}
+
+	if presharedKey == dumpValueNone {
+		presharedKey = ""
+	}
+
+	if endpoint == dumpValueNone {
+		endpoint = ""
+	}
+
+	var allowedIPList []string
+	if allowedIPs != dumpValueNone {
+		allowedIPList = strings.Split(allowedIPs, ",")
+	}
+
+	return &devicePeer{
+		PublicKey:           publicKey,
+		PresharedKey:        presharedKey,
+		Endpoint:            endpoint,
+		AllowedIPs:          allowedIPList,
+		LatestHandshakeUnix: latestHandshakeUnix,
+		TransferRx:          rx,
+		TransferTx:          tx,
+		PersistentKeepalive: keepalive,
+	}, nil
+}
@@ -0,0 +1,177 @@
+package wgwatcher
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseDump(t *testing.T) {
+	wg0 := device{
+		Name:         "wg0",
+		PublicKey:    "0aba10c5-5a5e-4182-b9cf-04b3cadd9208",
+		ListenPort:   8080,
+		FirewallMark: "off",
+		Peer: []devicePeer{
+			{
+				PublicKey:           "43236741-f343-4a58-a5f8-77dbef73ec45",
+				PresharedKey:        "4c1d9776-1a5c-49bb-99b3-63e9f9220196",
+				Endpoint:            "127.0.0.1:8080",
+				AllowedIPs:          []string{"127.0.0.1/32", "::1/128"},
+				LatestHandshakeUnix: 1647470666,
+				TransferRx:          153338920,
+				TransferTx:          3836305632,
+			},
+			{
+				PublicKey:    "d6a8f7f5-2d38-4093-ba0c-637a8d2fa9ed",
+				PresharedKey: "9cb789e0-a434-4ba1-9240-a23118d7fc4f",
+				AllowedIPs:   []string{"127.0.0.1/32", "::1/128"},
+			},
+			{
+				PublicKey:           "oxII3s0z+vjIZNGMGLDzVkGz+I6zZK1rnoLPOAfASGo=",
+				Endpoint:            "192.0.2.10:51000",
+				AllowedIPs:          []string{"10.8.0.4/32"},
+				LatestHandshakeUnix: 1647470700,
+				TransferRx:          4096,
+				TransferTx:          8192,
+				PersistentKeepalive: 25 * time.Second,
+			},
+		},
+	}
+
+	wg1 := device{
+		Name:         "wg1",
+		PublicKey:    "3b8d1f6e-2a4c-4b7e-9f1d-6e2c8a4b0d13",
+		ListenPort:   51820,
+		FirewallMark: "off",
+		Peer: []devicePeer{
+			{
+				PublicKey:           "8a1c4e7f-2b5d-4f9a-8c3e-6d0b2f4a7e15",
+				PresharedKey:        "5e0b7c2a-3f1d-4c8e-9a6b-2d4f8e1c7a90",
+				Endpoint:            "10.0.0.5:43120",
+				AllowedIPs:          []string{"10.8.1.2/32"},
+				LatestHandshakeUnix: 1647470600,
+				TransferRx:          1048576,
+				TransferTx:          2097152,
+				PersistentKeepalive: 25 * time.Second,
+			},
+			{
+				PublicKey:  "Zm9vYmFyYmF6cXV4c3RyYXlwZWVycHVibGlja2V5MDA=",
+				AllowedIPs: []string{"10.8.1.9/32"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		fixture string
+		raw     string
+		ifaces  []string
+		want    []device
+		wantErr bool
+	}{
+		{
+			name:    "single interface",
+			fixture: "dump",
+			ifaces:  []string{"wg0"},
+			want:    []device{wg0},
+		},
+		{
+			name:    "all interfaces",
+			fixture: "dump_all",
+			ifaces:  []string{"wg0", "wg1"},
+			want:    []device{wg0, wg1},
+		},
+		{
+			name:    "all interfaces, some watched",
+			fixture: "dump_all",
+			ifaces:  []string{"wg1"},
+			want:    []device{wg1},
+		},
+		{
+			name:    "single interface, many watched",
+			fixture: "dump",
+			ifaces:  []string{"wg0", "wg1"},
+			wantErr: true,
+		},
+		{
+			name:   "no peers",
+			raw:    "wg0\tprivate\tpublic\t51820\t0x1\n\n",
+			ifaces: []string{"wg0"},
+			want: []device{
+				{Name: "wg0", PublicKey: "public", ListenPort: 51820, FirewallMark: "0x1"},
+			},
+		},
+		{
+			name:    "unexpected token count",
+			raw:     "private\tpublic\t51820\n",
+			ifaces:  []string{"wg0"},
+			wantErr: true,
+		},
+		{
+			name:    "peer before interface",
+			raw:     "wg0\tpublic\t(none)\t(none)\t(none)\t0\t0\t0\toff\n",
+			ifaces:  []string{"wg0"},
+			wantErr: true,
+		},
+		{
+			name:    "malformed port",
+			raw:     "private\tpublic\tport\toff\n",
+			ifaces:  []string{"wg0"},
+			wantErr: true,
+		},
+		{
+			name: "malformed handshake",
+			raw: "private\tpublic\t51820\toff\n" +
+				"public\t(none)\t(none)\t(none)\tnever\t0\t0\toff\n",
+			ifaces:  []string{"wg0"},
+			wantErr: true,
+		},
+		{
+			name: "malformed transfer",
+			raw: "private\tpublic\t51820\toff\n" +
+				"public\t(none)\t(none)\t(none)\t0\t-\t0\toff\n",
+			ifaces:  []string{"wg0"},
+			wantErr: true,
+		},
+		{
+			name: "malformed keepalive",
+			raw: "private\tpublic\t51820\toff\n" +
+				"public\t(none)\t(none)\t(none)\t0\t0\t0\ton\n",
+			ifaces:  []string{"wg0"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := []byte(tt.raw)
+			if tt.fixture != "" {
+				var err error
+
+				raw, err = os.ReadFile("../../../etc/fixture/wg/" + tt.fixture)
+				if err != nil {
+					t.Fatalf("can't read fixture: %v", err)
+				}
+			}
+
+			got, err := parseDump(raw, tt.ifaces)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseDump() = %+v, want error", got)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("parseDump() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseDump() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
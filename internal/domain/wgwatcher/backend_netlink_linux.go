@@ -0,0 +1,300 @@
+package wgwatcher
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// The generic netlink and WireGuard constants, see linux/genetlink.h and linux/wireguard.h.
+const (
+	genlIDCtrl           = 0x10
+	genlCtrlCmdGetFamily = 3
+	genlCtrlVersion      = 1
+	genlCtrlAttrFamilyID = 1
+	genlCtrlAttrFamName  = 2
+	genlHeaderLength     = 4
+
+	wgGenlName    = "wireguard"
+	wgGenlVersion = 1
+
+	wgCmdGetDevice = 0
+	wgCmdSetDevice = 1
+
+	wgDeviceAttrIfname     = 2
+	wgDeviceAttrPublicKey  = 4
+	wgDeviceAttrListenPort = 6
+	wgDeviceAttrFwmark     = 7
+	wgDeviceAttrPeers      = 8
+
+	wgPeerAttrPublicKey           = 1
+	wgPeerAttrPresharedKey        = 2
+	wgPeerAttrFlags               = 3
+	wgPeerAttrEndpoint            = 4
+	wgPeerAttrPersistentKeepalive = 5
+	wgPeerAttrLastHandshakeTime   = 6
+	wgPeerAttrRxBytes             = 7
+	wgPeerAttrTxBytes             = 8
+	wgPeerAttrAllowedIPs          = 9
+
+	wgPeerFlagRemoveMe          = 1 << 0
+	wgPeerFlagReplaceAllowedIPs = 1 << 1
+
+	wgAllowedIPAttrFamily   = 1
+	wgAllowedIPAttrIPAddr   = 2
+	wgAllowedIPAttrCIDRMask = 3
+
+	netlinkTimeout    = 2 * time.Second
+	netlinkBufferSize = 32 * 1024
+)
+
+// nativeEndian is the byte order of the netlink values, the ports of the endpoints are big endian though.
+var nativeEndian = func() binary.ByteOrder {
+	probe := uint16(1)
+	if *(*byte)(unsafe.Pointer(&probe)) == 1 {
+		return binary.LittleEndian
+	}
+
+	return binary.BigEndian
+}()
+
+// netlinkBackend speaks the WireGuard generic netlink family, it's what `wg` does under the hood.
+type netlinkBackend struct {
+	ifaces []string
+}
+
+func newNetlinkBackend(ifaces []string) *netlinkBackend {
+	return &netlinkBackend{
+		ifaces: ifaces,
+	}
+}
+
+func (b *netlinkBackend) GetDevices(ctx context.Context) ([]device, error) {
+	conn, familyID, err := dialWireGuardNetlink(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	devices := make([]device, 0, len(b.ifaces))
+	for _, iface := range b.ifaces {
+		msgs, err := conn.Execute(
+			familyID,
+			syscall.NLM_F_REQUEST|syscall.NLM_F_DUMP,
+			wgCmdGetDevice,
+			encodeNetlinkAttr(wgDeviceAttrIfname, encodeNetlinkString(iface)),
+		)
+		if err != nil {
+			// The interface isn't running, it's flagged down rather than failing the others.
+			if errors.Is(err, syscall.ENODEV) {
+				continue
+			}
+
+			return nil, fmt.Errorf("can't get device %q: %w", iface, err)
+		}
+
+		dev, err := decodeDevice(msgs)
+		if err != nil {
+			return nil, fmt.Errorf("can't decode device %q: %w", iface, err)
+		}
+
+		dev.Name = iface
+		devices = append(devices, *dev)
+	}
+
+	return devices, nil
+}
+
+func (b *netlinkBackend) AddPeer(ctx context.Context, iface string, spec PeerSpec) error {
+	publicKey, err := decodeKey(spec.PublicKey)
+	if err != nil {
+		return fmt.Errorf("invalid public key: %w", err)
+	}
+
+	peerAttrs := [][]byte{
+		encodeNetlinkAttr(wgPeerAttrPublicKey, publicKey),
+		encodeNetlinkAttr(wgPeerAttrFlags, encodeNetlinkUint32(wgPeerFlagReplaceAllowedIPs)),
+	}
+
+	if spec.PresharedKey != "" && spec.PresharedKey != dumpValueNone {
+		presharedKey, err := decodeKey(spec.PresharedKey)
+		if err != nil {
+			return fmt.Errorf("invalid preshared key: %w", err)
+		}
+
+		peerAttrs = append(peerAttrs, encodeNetlinkAttr(wgPeerAttrPresharedKey, presharedKey))
+	}
+
+	if spec.PersistentKeepalive != "" && spec.PersistentKeepalive != dumpValueOff {
+		keepalive, err := parseUint16(spec.PersistentKeepalive)
+		if err != nil {
+			return fmt.Errorf("invalid keepalive: %w", err)
+		}
+
+		peerAttrs = append(peerAttrs, encodeNetlinkAttr(wgPeerAttrPersistentKeepalive, encodeNetlinkUint16(keepalive)))
+	}
+
+	if spec.AllowedIPs != "" && spec.AllowedIPs != dumpValueNone {
+		allowedIPs, err := encodeAllowedIPs(spec.AllowedIPs)
+		if err != nil {
+			return fmt.Errorf("invalid allowed ips: %w", err)
+		}
+
+		peerAttrs = append(peerAttrs, encodeNetlinkNestedAttr(wgPeerAttrAllowedIPs, allowedIPs...))
+	}
+
+	return b.setPeer(ctx, iface, peerAttrs)
+}
+
+func (b *netlinkBackend) RemovePeer(ctx context.Context, iface, publicKey string) error {
+	rawPublicKey, err := decodeKey(publicKey)
+	if err != nil {
+		return fmt.Errorf("invalid public key: %w", err)
+	}
+
+	return b.setPeer(ctx, iface, [][]byte{
+		encodeNetlinkAttr(wgPeerAttrPublicKey, rawPublicKey),
+		encodeNetlinkAttr(wgPeerAttrFlags, encodeNetlinkUint32(wgPeerFlagRemoveMe)),
+	})
+}
+
+func (b *netlinkBackend) setPeer(ctx context.Context, iface string, peerAttrs [][]byte) error {
+	conn, familyID, err := dialWireGuardNetlink(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	payload := append(
+		encodeNetlinkAttr(wgDeviceAttrIfname, encodeNetlinkString(iface)),
+		encodeNetlinkNestedAttr(wgDeviceAttrPeers, encodeNetlinkNestedAttr(0, peerAttrs...))...,
+	)
+
+	if _, err = conn.Execute(familyID, syscall.NLM_F_REQUEST|syscall.NLM_F_ACK, wgCmdSetDevice, payload); err != nil {
+		return fmt.Errorf("can't set device %q: %w", iface, err)
+	}
+
+	return nil
+}
+
+// dialWireGuardNetlink opens the generic netlink socket and resolves the WireGuard family on it.
+func dialWireGuardNetlink(ctx context.Context) (*netlinkConn, uint16, error) {
+	conn, err := dialNetlink(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("can't dial netlink: %w", err)
+	}
+
+	familyID, err := conn.ResolveFamily(wgGenlName)
+	if err != nil {
+		conn.Close()
+
+		return nil, 0, fmt.Errorf("can't resolve family: %w", err)
+	}
+
+	return conn, familyID, nil
+}
+
+func decodeDevice(msgs []syscall.NetlinkMessage) (*device, error) {
+	var dev device
+	for _, msg := range msgs {
+		if len(msg.Data) < genlHeaderLength {
+			return nil, fmt.Errorf("unexpected message length: %d", len(msg.Data))
+		}
+
+		attrs, err := decodeNetlinkAttrs(msg.Data[genlHeaderLength:])
+		if err != nil {
+			return nil, fmt.Errorf("can't decode device attrs: %w", err)
+		}
+
+		for _, attr := range attrs {
+			switch attr.Type {
+			case wgDeviceAttrPublicKey:
+				dev.PublicKey = encodeKey(attr.Data)
+			case wgDeviceAttrListenPort:
+				dev.ListenPort = int64(decodeNetlinkUint16(attr.Data))
+			case wgDeviceAttrFwmark:
+				dev.FirewallMark = dumpValueOff
+				if fwmark := decodeNetlinkUint32(attr.Data); fwmark != 0 {
+					dev.FirewallMark = fmt.Sprintf("0x%x", fwmark)
+				}
+			case wgDeviceAttrPeers:
+				if err = decodeDevicePeers(&dev, attr.Data); err != nil {
+					return nil, fmt.Errorf("can't decode peers: %w", err)
+				}
+			}
+		}
+	}
+
+	return &dev, nil
+}
+
+// decodeDevicePeers appends the peers to the device. The peer with many allowed ips may be split
+// across the messages, then the next message goes on with the same public key.
+func decodeDevicePeers(dev *device, b []byte) error {
+	peerAttrs, err := decodeNetlinkAttrs(b)
+	if err != nil {
+		return fmt.Errorf("can't decode peer list: %w", err)
+	}
+
+	for _, peerAttr := range peerAttrs {
+		peer, err := decodeDevicePeer(peerAttr.Data)
+		if err != nil {
+			return fmt.Errorf("can't decode peer: %w", err)
+		}
+
+		if len(dev.Peer) != 0 && dev.Peer[len(dev.Peer)-1].PublicKey == peer.PublicKey {
+			last := &dev.Peer[len(dev.Peer)-1]
+			last.AllowedIPs = append(last.AllowedIPs, peer.AllowedIPs...)
+
+			continue
+		}
+
+		dev.Peer = append(dev.Peer, *peer)
+	}
+
+	return nil
+}
+
+func decodeDevicePeer(b []byte) (*devicePeer, error) {
+	attrs, err := decodeNetlinkAttrs(b)
+	if err != nil {
+		return nil, fmt.Errorf("can't decode attrs: %w", err)
+	}
+
+	var peer devicePeer
+	for _, attr := range attrs {
+		switch attr.Type {
+		case wgPeerAttrPublicKey:
+			peer.PublicKey = encodeKey(attr.Data)
+		case wgPeerAttrPresharedKey:
+			if !isZeroKey(attr.Data) {
+				peer.PresharedKey = encodeKey(attr.Data)
+			}
+		case wgPeerAttrEndpoint:
+			peer.Endpoint, err = decodeSockaddr(attr.Data)
+			if err != nil {
+				return nil, fmt.Errorf("can't decode endpoint: %w", err)
+			}
+		case wgPeerAttrPersistentKeepalive:
+			peer.PersistentKeepalive = time.Duration(decodeNetlinkUint16(attr.Data)) * time.Second
+		case wgPeerAttrLastHandshakeTime:
+			// It's the kernel timespec, the seconds go first.
+			peer.LatestHandshakeUnix = int64(decodeNetlinkUint64(attr.Data))
+		case wgPeerAttrRxBytes:
+			peer.TransferRx = int64(decodeNetlinkUint64(attr.Data))
+		case wgPeerAttrTxBytes:
+			peer.TransferTx = int64(decodeNetlinkUint64(attr.Data))
+		case wgPeerAttrAllowedIPs:
+			peer.AllowedIPs, err = decodeAllowedIPs(attr.Data)
+			if err != nil {
+				return nil, fmt.Errorf("can't decode allowed ips: %w", err)
+			}
+		}
+	}
+
+	return &peer, nil
+}
@@ -0,0 +1,26 @@
+//go:build !linux
+
+package wgwatcher
+
+import (
+	"context"
+)
+
+// netlinkBackend is available on Linux only, elsewhere it fails every call.
+type netlinkBackend struct{}
+
+func newNetlinkBackend(_ []string) *netlinkBackend {
+	return &netlinkBackend{}
+}
+
+func (b *netlinkBackend) GetDevices(_ context.Context) ([]device, error) {
+	return nil, ErrUnsupportedBackend
+}
+
+func (b *netlinkBackend) AddPeer(_ context.Context, _ string, _ PeerSpec) error {
+	return ErrUnsupportedBackend
+}
+
+func (b *netlinkBackend) RemovePeer(_ context.Context, _, _ string) error {
+	return ErrUnsupportedBackend
+}
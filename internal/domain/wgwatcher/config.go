@@ -1,17 +1,42 @@
 package wgwatcher
 
 import (
+	"fmt"
+	"os"
 	"regexp"
+	"strings"
+	"time"
+	"unicode"
 
 	"github.com/kelseyhightower/envconfig"
 )
 
 type Config struct {
+	// Backend is either "exec", running Cmd with CmdArgs to get the dump, or "netlink",
+	// talking to the WireGuard generic netlink family directly.
+	Backend string `split_words:"true" default:"exec"`
+
+	// Interface is the single interface of the older setups, it's used only if Interfaces are empty.
+	Interface   string   `split_words:"true" default:"wg0"`
 	Cmd         string   `split_words:"true"`
 	CmdArgs     []string `split_words:"true"`
 	ConfDirPath string   `split_words:"true"`
 	ConfPattern string   `split_words:"true"`
 
+	// Interfaces, if set, are watched instead of the single Interface. The conf dir path and pattern
+	// of every interface may be overridden by WG_CONF_DIR_PATH_<INTERFACE> and WG_CONF_PATTERN_<INTERFACE>,
+	// otherwise ConfDirPath and ConfPattern are used. Many interfaces need either override each.
+	// The patterns are regexps, so they can't be listed in a single variable.
+	Interfaces []string `split_words:"true"`
+
+	RateWindow time.Duration `split_words:"true" default:"10s"`
+
+	InterfaceConfs []InterfaceConf
+}
+
+type InterfaceConf struct {
+	Name          string
+	ConfDirPath   string
 	ConfPatternRe *regexp.Regexp
 }
 
@@ -19,7 +44,59 @@ func MustNewConfig() Config {
 	var cfg Config
 	envconfig.MustProcess("wg", &cfg)
 
-	cfg.ConfPatternRe = regexp.MustCompile(cfg.ConfPattern)
+	switch cfg.Backend {
+	case backendExec, backendNetlink:
+	default:
+		panic(fmt.Sprintf("unexpected backend %q", cfg.Backend))
+	}
+
+	ifaces := cfg.Interfaces
+	if len(ifaces) == 0 {
+		ifaces = []string{cfg.Interface}
+	}
+
+	for _, iface := range ifaces {
+		ifaceConf := InterfaceConf{
+			Name:          iface,
+			ConfDirPath:   cfg.ConfDirPath,
+			ConfPatternRe: regexp.MustCompile(cfg.ConfPattern),
+		}
+
+		var (
+			confDirPathKey = interfaceEnvKey("CONF_DIR_PATH", iface)
+			confPatternKey = interfaceEnvKey("CONF_PATTERN", iface)
+		)
+
+		confDirPath, dirOverridden := os.LookupEnv(confDirPathKey)
+		if dirOverridden {
+			ifaceConf.ConfDirPath = confDirPath
+		}
+
+		confPattern, patternOverridden := os.LookupEnv(confPatternKey)
+		if patternOverridden {
+			ifaceConf.ConfPatternRe = regexp.MustCompile(confPattern)
+		}
+
+		// The interfaces sharing the confs would have every peer twice, the names are unique across them.
+		if len(ifaces) > 1 && !dirOverridden && !patternOverridden {
+			panic(fmt.Sprintf("interface %q needs %s or %s", iface, confPatternKey, confDirPathKey))
+		}
+
+		cfg.InterfaceConfs = append(cfg.InterfaceConfs, ifaceConf)
+	}
 
 	return cfg
 }
+
+// interfaceEnvKey returns the variable of the interface, e.g. WG_CONF_PATTERN_WG0 for CONF_PATTERN of wg0.
+func interfaceEnvKey(key, iface string) string {
+	suffix := strings.Map(func(r rune) rune {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return '_'
+		}
+
+		return unicode.ToUpper(r)
+	}, iface)
+
+	return fmt.Sprintf("WG_%s_%s", key, suffix)
+}
@@ -0,0 +1,232 @@
+package wgwatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// RemovePeer removes the peer from the running interface, the returned ref is enough to add it back.
+// The client config is kept as is, so the peer is back on the next restart of the interface.
+func (d *Domain) RemovePeer(ctx context.Context, name string) (*PeerRef, error) {
+	d.guard()
+
+	d.mux.RLock()
+	spec, found := d.peerSpecAccessor[name]
+	d.mux.RUnlock()
+
+	if !found {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownPeer, name)
+	}
+
+	err := d.backend.RemovePeer(ctx, spec.Interface, spec.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("can't remove peer: %w", err)
+	}
+
+	return &PeerRef{
+		Name:                spec.Name,
+		Interface:           spec.Interface,
+		PublicKey:           spec.PublicKey,
+		AllowedIPs:          spec.AllowedIPs,
+		PersistentKeepalive: spec.PersistentKeepalive,
+	}, nil
+}
+
+// AddPeer adds the peer to the running interface. The preshared key is read from the client conf,
+// the peer without conf or with the conf of another key is unknown.
+func (d *Domain) AddPeer(ctx context.Context, ref PeerRef) error {
+	d.guard()
+
+	// Refs saved before multiple interfaces were supported have no interface.
+	iface := ref.Interface
+	if iface == "" {
+		iface = d.cfg.InterfaceConfs[0].Name
+	}
+
+	ifaceConf, err := d.GetInterfaceConf(iface)
+	if err != nil {
+		return err
+	}
+
+	spec, err := getConfPeerSpec(*ifaceConf, ref)
+	if err != nil {
+		return err
+	}
+
+	if err = d.backend.AddPeer(ctx, iface, *spec); err != nil {
+		return fmt.Errorf("can't add peer: %w", err)
+	}
+
+	return nil
+}
+
+// GetConfPeerRef returns the ref of the peer built from its client conf, so the peer missed
+// on the interface, e.g. the disabled one or the one of the interface down, is still known.
+// The public key is empty if the conf has no keys.
+func (d *Domain) GetConfPeerRef(name string) (*PeerRef, error) {
+	d.guard()
+
+	for _, ifaceConf := range d.cfg.InterfaceConfs {
+		conf, publicKey, err := readPeerConf(ifaceConf, name)
+		if err != nil {
+			if errors.Is(err, ErrUnknownPeer) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		ref := PeerRef{
+			Name:      name,
+			Interface: ifaceConf.Name,
+			PublicKey: publicKey,
+		}
+
+		if conf.Interface.Address != "" {
+			ref.AllowedIPs, err = castHostPrefixes(conf.Interface.Address)
+			if err != nil {
+				return nil, fmt.Errorf("can't cast address of %q: %w", name, err)
+			}
+		}
+
+		return &ref, nil
+	}
+
+	return nil, fmt.Errorf("%w: %q has no conf", ErrUnknownPeer, name)
+}
+
+// getConfPeerSpec builds the spec of the peer from the ref and the preshared key of its client conf.
+// The refs saved without allowed IPs fall back to the addresses of the client.
+func getConfPeerSpec(ifaceConf InterfaceConf, ref PeerRef) (*PeerSpec, error) {
+	conf, publicKey, err := readPeerConf(ifaceConf, ref.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	// The name may be taken by another peer since the revocation, its conf is no use then.
+	if publicKey != "" && publicKey != ref.PublicKey {
+		return nil, fmt.Errorf("%w: conf of %q has another key", ErrUnknownPeer, ref.Name)
+	}
+
+	spec := PeerSpec{
+		Name:                ref.Name,
+		Interface:           ifaceConf.Name,
+		PublicKey:           ref.PublicKey,
+		PresharedKey:        conf.Peer.PresharedKey,
+		AllowedIPs:          ref.AllowedIPs,
+		PersistentKeepalive: ref.PersistentKeepalive,
+	}
+
+	if spec.PresharedKey == "" {
+		spec.PresharedKey = dumpValueNone
+	}
+
+	if spec.PersistentKeepalive == "" {
+		spec.PersistentKeepalive = dumpValueOff
+	}
+
+	if spec.AllowedIPs == "" {
+		spec.AllowedIPs = dumpValueNone
+
+		if conf.Interface.Address != "" {
+			spec.AllowedIPs, err = castHostPrefixes(conf.Interface.Address)
+			if err != nil {
+				return nil, fmt.Errorf("can't cast address of %q: %w", ref.Name, err)
+			}
+		}
+	}
+
+	return &spec, nil
+}
+
+// readPeerConf reads the client conf of the peer along with its public key, the key is empty
+// if the conf has neither the private nor the public one.
+func readPeerConf(ifaceConf InterfaceConf, name string) (*iniConf, string, error) {
+	var confPath string
+
+	err := fs.WalkDir(os.DirFS(ifaceConf.ConfDirPath), ".", func(ep string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("fs error at %q: %w", ep, err)
+		}
+
+		if e.IsDir() {
+			return nil
+		}
+
+		match := ifaceConf.ConfPatternRe.FindStringSubmatch(e.Name())
+		if len(match) != 2 || match[1] != name {
+			return nil
+		}
+
+		confPath = path.Join(ifaceConf.ConfDirPath, ep)
+
+		return fs.SkipAll
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("can't walk dir at %q: %w", ifaceConf.ConfDirPath, err)
+	}
+
+	if confPath == "" {
+		return nil, "", fmt.Errorf("%w: %q has no conf", ErrUnknownPeer, name)
+	}
+
+	var conf iniConf
+	if err = ini.MapTo(&conf, confPath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, "", fmt.Errorf("%w: %q has no conf", ErrUnknownPeer, name)
+		}
+
+		return nil, "", fmt.Errorf("can't map conf at %q: %w", confPath, err)
+	}
+
+	publicKey := conf.Interface.PublicKey
+	if conf.Interface.PrivateKey != "" {
+		publicKey, err = derivePublicKey(conf.Interface.PrivateKey)
+		if err != nil {
+			return nil, "", fmt.Errorf("can't derive public key of %q: %w", name, err)
+		}
+	}
+
+	return &conf, publicKey, nil
+}
+
+// castHostPrefixes turns the comma separated addresses of the client into the host prefixes,
+// the peer is allowed to use its own addresses only.
+func castHostPrefixes(address string) (string, error) {
+	var prefixes []string
+	for _, raw := range strings.Split(address, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+
+		if i := strings.IndexByte(raw, '/'); i != -1 {
+			raw = raw[:i]
+		}
+
+		ip := net.ParseIP(raw)
+		if ip == nil {
+			return "", fmt.Errorf("invalid address: %q", raw)
+		}
+
+		if ip.To4() != nil {
+			prefixes = append(prefixes, ip.String()+"/32")
+		} else {
+			prefixes = append(prefixes, ip.String()+"/128")
+		}
+	}
+
+	if len(prefixes) == 0 {
+		return "", errors.New("no address")
+	}
+
+	return strings.Join(prefixes, ","), nil
+}
@@ -4,3 +4,7 @@ type PersistorDomain interface {
 	Save(tag string, b []byte) error
 	Load(tag string) ([]byte, error)
 }
+
+type EventPublisher interface {
+	Publish(event interface{})
+}
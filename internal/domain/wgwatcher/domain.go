@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/whiteforestz/iino/internal/pkg/eventbus"
 	"github.com/whiteforestz/iino/internal/pkg/infloop"
 	"github.com/whiteforestz/iino/internal/pkg/logger"
 	"go.uber.org/zap"
@@ -13,6 +14,10 @@ import (
 
 const (
 	tickerPeriod = 1 * time.Second
+
+	eventSource      = "wgwatcher"
+	collectorBackend = "backend"
+	collectorUsage   = "usage"
 )
 
 type Domain struct {
@@ -20,24 +25,35 @@ type Domain struct {
 	finished  chan struct{}
 	cfg       Config
 	persistor PersistorDomain
-
-	prepared             bool
-	snapshotPeerAccessor map[string]snapshotPeer
-	mux                  *sync.RWMutex
-	usage                Usage
+	events    EventPublisher
+	backend   backend
+
+	prepared              bool
+	snapshotPeerAccessor  map[string]snapshotPeer
+	transferSamples       map[string][]peerTransferSample
+	unmanagedPeerAccessor map[string]struct{}
+	peerOnlineAccessor    map[string]bool
+	mux                   *sync.RWMutex
+	usage                 Usage
+	peerSpecAccessor      map[string]PeerSpec
 }
 
 func New(
 	cfg Config,
 	persistorDomain PersistorDomain,
+	eventPublisher EventPublisher,
 ) *Domain {
 	return &Domain{
 		started:   make(chan struct{}),
 		finished:  make(chan struct{}),
 		cfg:       cfg,
 		persistor: persistorDomain,
+		events:    eventPublisher,
+		backend:   newBackend(cfg),
 
-		mux: &sync.RWMutex{},
+		transferSamples:    make(map[string][]peerTransferSample),
+		peerOnlineAccessor: make(map[string]bool),
+		mux:                &sync.RWMutex{},
 	}
 }
 
@@ -73,15 +89,34 @@ func (d *Domain) GetUsage() (*Usage, error) {
 	d.mux.RLock()
 	defer d.mux.RUnlock()
 
-	if len(d.usage.Peer) == 0 {
+	if len(d.usage.Interface) == 0 {
 		return nil, ErrEmptyUsage
 	}
 
-	usage.Peer = append(usage.Peer, d.usage.Peer...)
+	for _, iface := range d.usage.Interface {
+		iface.Peer = append([]Peer(nil), iface.Peer...)
+		usage.Interface = append(usage.Interface, iface)
+	}
 
 	return &usage, nil
 }
 
+// GetInterfaceConf returns the conf of the watched interface.
+func (d *Domain) GetInterfaceConf(name string) (*InterfaceConf, error) {
+	for _, ifaceConf := range d.cfg.InterfaceConfs {
+		if ifaceConf.Name == name {
+			return &ifaceConf, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrUnknownInterface, name)
+}
+
+// GetInterfaceConfs returns the confs of every watched interface.
+func (d *Domain) GetInterfaceConfs() []InterfaceConf {
+	return append([]InterfaceConf(nil), d.cfg.InterfaceConfs...)
+}
+
 func (d *Domain) loop(ctx context.Context) {
 	ticker := time.NewTicker(tickerPeriod)
 	defer ticker.Stop()
@@ -96,11 +131,21 @@ func (d *Domain) loop(ctx context.Context) {
 		OnTick: func(ctx context.Context) {
 			if err := d.updateUsage(ctx); err != nil {
 				logger.Instance().Error("can't update usage", zap.Error(err))
+				d.publishCollectorFailed(collectorUsage, err)
 			}
 		},
 	})
 }
 
+func (d *Domain) publishCollectorFailed(collector string, err error) {
+	d.events.Publish(eventbus.CollectorFailed{
+		Source:    eventSource,
+		Collector: collector,
+		Err:       err,
+		At:        time.Now(),
+	})
+}
+
 func (d *Domain) guard() {
 	if !d.prepared {
 		panic("unprepared domain")
@@ -0,0 +1,68 @@
+package wgwatcher
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/whiteforestz/iino/internal/pkg/logger"
+)
+
+const (
+	endpointHistoryLimit = 10
+)
+
+// enrichUsagePeerEndpoint tracks endpoint changes of the roaming peers, the history is kept in the snapshot.
+func (d *Domain) enrichUsagePeerEndpoint(usage []Peer, at time.Time) []Peer {
+	enrichedUsage := make([]Peer, 0, len(usage))
+	for _, peer := range usage {
+		var snapshot snapshotPeer
+		if !peer.Unmanaged {
+			snapshot = d.snapshotPeerAccessor[peer.Name]
+		}
+
+		history := make([]EndpointChange, 0, len(snapshot.EndpointHistory)+1)
+		for _, change := range snapshot.EndpointHistory {
+			history = append(history, EndpointChange{
+				Endpoint:      change.Endpoint,
+				ChangedAtUnix: change.ChangedAtUnix,
+			})
+		}
+
+		// The snapshots saved before the count was kept have the roams in the history only.
+		roamCount := snapshot.RoamCount
+		if roamCount == 0 && len(history) > 1 {
+			roamCount = int64(len(history) - 1)
+		}
+
+		// The endpoint is unknown before the first handshake and it isn't a change.
+		if peer.Endpoint != "" && (len(history) == 0 || history[len(history)-1].Endpoint != peer.Endpoint) {
+			if len(history) != 0 {
+				roamCount++
+
+				logger.Instance().Info(
+					"peer endpoint changed",
+					zap.String("peer", peer.Name),
+					zap.String("from", history[len(history)-1].Endpoint),
+					zap.String("to", peer.Endpoint),
+				)
+			}
+
+			history = append(history, EndpointChange{
+				Endpoint:      peer.Endpoint,
+				ChangedAtUnix: at.Unix(),
+			})
+		}
+
+		if len(history) > endpointHistoryLimit {
+			history = history[len(history)-endpointHistoryLimit:]
+		}
+
+		peer.EndpointHistory = history
+		peer.RoamCount = roamCount
+
+		enrichedUsage = append(enrichedUsage, peer)
+	}
+
+	return enrichedUsage
+}
@@ -3,5 +3,8 @@ package wgwatcher
 import "errors"
 
 var (
-	ErrEmptyUsage = errors.New("empty usage")
+	ErrEmptyUsage         = errors.New("empty usage")
+	ErrUnknownPeer        = errors.New("unknown peer")
+	ErrUnknownInterface   = errors.New("unknown interface")
+	ErrUnsupportedBackend = errors.New("unsupported backend")
 )
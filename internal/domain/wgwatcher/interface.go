@@ -0,0 +1,101 @@
+package wgwatcher
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/whiteforestz/iino/internal/pkg/logger"
+)
+
+func (d *Domain) groupUsageByInterface(ifaceAccessor map[string]InterfaceUsage, usage []Peer) []InterfaceUsage {
+	d.mux.RLock()
+	lastIfaceUsage := d.usage.Interface
+	d.mux.RUnlock()
+
+	nowUnix := time.Now().Unix()
+
+	ifaceUsage := make([]InterfaceUsage, 0, len(d.cfg.InterfaceConfs))
+	for i, ifaceConf := range d.cfg.InterfaceConfs {
+		iface, found := ifaceAccessor[ifaceConf.Name]
+		if !found {
+			// The whole dump misses the interface, so it isn't running.
+			iface = InterfaceUsage{
+				Name: ifaceConf.Name,
+			}
+			if i < len(lastIfaceUsage) {
+				iface = lastIfaceUsage[i]
+			}
+
+			iface, changed := markInterfaceDown(iface, nowUnix)
+			if changed {
+				logger.Instance().Warn("interface is down", zap.String("interface", iface.Name))
+			}
+
+			ifaceUsage = append(ifaceUsage, iface)
+
+			continue
+		}
+
+		if i < len(lastIfaceUsage) && !lastIfaceUsage[i].Up && lastIfaceUsage[i].DownSinceUnix != 0 {
+			logger.Instance().Info("interface is up", zap.String("interface", iface.Name))
+		}
+
+		iface.Up = true
+		for _, peer := range usage {
+			if peer.Interface == ifaceConf.Name {
+				iface.Peer = append(iface.Peer, peer)
+			}
+		}
+
+		ifaceUsage = append(ifaceUsage, iface)
+	}
+
+	return ifaceUsage
+}
+
+// setInterfacesDown flags all the interfaces down keeping the last known peers,
+// the failure is logged once per transition rather than on every tick.
+func (d *Domain) setInterfacesDown(cause error) {
+	nowUnix := time.Now().Unix()
+
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	var (
+		ifaceUsage = make([]InterfaceUsage, 0, len(d.cfg.InterfaceConfs))
+		changed    bool
+	)
+	for i, ifaceConf := range d.cfg.InterfaceConfs {
+		iface := InterfaceUsage{
+			Name: ifaceConf.Name,
+		}
+		if i < len(d.usage.Interface) {
+			iface = d.usage.Interface[i]
+		}
+
+		var ifaceChanged bool
+		iface, ifaceChanged = markInterfaceDown(iface, nowUnix)
+		changed = changed || ifaceChanged
+
+		ifaceUsage = append(ifaceUsage, iface)
+	}
+
+	if changed {
+		logger.Instance().Error("can't exec cmd, interfaces are down", zap.Error(cause))
+	}
+
+	d.usage.Interface = ifaceUsage
+}
+
+// markInterfaceDown flags the interface down, it reports whether the interface has just gone down.
+func markInterfaceDown(iface InterfaceUsage, nowUnix int64) (InterfaceUsage, bool) {
+	changed := iface.Up || iface.DownSinceUnix == 0
+	if changed {
+		iface.DownSinceUnix = nowUnix
+	}
+
+	iface.Up = false
+
+	return iface, changed
+}
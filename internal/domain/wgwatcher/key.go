@@ -0,0 +1,54 @@
+package wgwatcher
+
+import (
+	"crypto/ecdh"
+	"encoding/base64"
+	"fmt"
+)
+
+const (
+	wgKeyLength = 32
+)
+
+// derivePublicKey returns the base64 encoded public key of the base64 encoded private key the way `wg pubkey` does.
+func derivePublicKey(privateKey string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(privateKey)
+	if err != nil {
+		return "", fmt.Errorf("can't decode base64: %w", err)
+	}
+
+	key, err := ecdh.X25519().NewPrivateKey(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid private key: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(key.PublicKey().Bytes()), nil
+}
+
+func encodeKey(raw []byte) string {
+	return base64.StdEncoding.EncodeToString(raw)
+}
+
+func decodeKey(key string) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("can't decode base64: %w", err)
+	}
+
+	if len(raw) != wgKeyLength {
+		return nil, fmt.Errorf("unexpected key length: %d", len(raw))
+	}
+
+	return raw, nil
+}
+
+// isZeroKey reports whether the key isn't set, the kernel reports unset keys as zeros.
+func isZeroKey(raw []byte) bool {
+	for _, b := range raw {
+		if b != 0 {
+			return false
+		}
+	}
+
+	return true
+}
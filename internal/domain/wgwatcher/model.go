@@ -1,18 +1,115 @@
 package wgwatcher
 
+import (
+	"time"
+)
+
 type Usage struct {
-	Peer []Peer
+	Interface []InterfaceUsage
+}
+
+// Peers returns peers of all the interfaces.
+func (u *Usage) Peers() []Peer {
+	var peers []Peer
+	for _, iface := range u.Interface {
+		peers = append(peers, iface.Peer...)
+	}
+
+	return peers
+}
+
+type InterfaceUsage struct {
+	Name          string
+	Up            bool  // Up is false when the dump fails or misses the interface, the peers are the last known then.
+	DownSinceUnix int64 // DownSinceUnix is when the interface was found down first.
+	PublicKey     string
+	ListenPort    int64
+	FirewallMark  string
+	PeerCount     int // PeerCount is the number of peers of the running interface including the unmanaged ones.
+	Peer          []Peer
 }
 
 type Peer struct {
 	Name                string
+	Unmanaged           bool // Unmanaged is true for the peer without conf, it's named by the truncated public key then.
+	Interface           string
 	LatestHandshakeUnix int64
 	TransferRx          int64
 	TransferTx          int64
+	TransferRxTotal     int64            // TransferRxTotal is bytes received since the peer was seen first, it survives interface restarts.
+	TransferTxTotal     int64            // TransferTxTotal is bytes sent since the peer was seen first, it survives interface restarts.
+	TransferRxRate      int64            // TransferRxRate is bytes per second received over the latest tick.
+	TransferTxRate      int64            // TransferTxRate is bytes per second sent over the latest tick.
+	TransferRxRateAvg   int64            // TransferRxRateAvg is bytes per second received over the rate window.
+	TransferTxRateAvg   int64            // TransferTxRateAvg is bytes per second sent over the rate window.
+	Endpoint            string           // Endpoint is the latest address of the peer, it's empty until the first handshake.
+	EndpointHistory     []EndpointChange // EndpointHistory is the latest endpoint changes, the oldest go first.
+	RoamCount           int64            // RoamCount is the endpoint changes since the first endpoint, the history is capped unlike it.
+	AllowedIPs          []string
+	PersistentKeepalive time.Duration // PersistentKeepalive is zero when it's off.
+}
+
+type EndpointChange struct {
+	Endpoint      string
+	ChangedAtUnix int64
+}
+
+// PeerSpec is everything needed to put the peer back to the running interface,
+// the values are kept in the format of the dump.
+type PeerSpec struct {
+	Name                string
+	Interface           string
+	PublicKey           string
+	PresharedKey        string
+	AllowedIPs          string
+	PersistentKeepalive string
+}
+
+// PeerRef identifies the peer removed from the running interface, the values are kept in the format
+// of the dump. The preshared key is read from the client conf when the peer is added back,
+// so the ref is safe to persist.
+type PeerRef struct {
+	Name                string `json:"name"`
+	Interface           string `json:"interface"`
+	PublicKey           string `json:"publicKey"`
+	AllowedIPs          string `json:"allowedIPs,omitempty"`
+	PersistentKeepalive string `json:"persistentKeepalive,omitempty"`
+}
+
+// PeerStateChanged is the transition of the peer between online and offline, the endpoint is the latest one.
+type PeerStateChanged struct {
+	Name      string
+	Interface string
+	Online    bool
+	Endpoint  string
+	AtUnix    int64
+}
+
+// currentUsage is the parsed dump, the interfaces are keyed by name.
+type currentUsage struct {
+	Interface map[string]InterfaceUsage
+	Peer      []Peer
+	PeerSpec  map[string]PeerSpec
+}
+
+// peerNameAccessor returns peer names by public keys, the preshared keys are a fallback
+// for the confs without keys of the peer.
+type peerNameAccessor struct {
+	ByPublicKey    map[string]string
+	ByPresharedKey map[string]string
 }
 
 type iniConf struct {
-	Peer iniPeer `ini:"Peer"`
+	Interface iniInterface `ini:"Interface"`
+	Peer      iniPeer      `ini:"Peer"`
+}
+
+// iniInterface is the client side of the peer, the public key isn't a part of the WireGuard format
+// and may be set for the confs without private keys.
+type iniInterface struct {
+	PrivateKey string `ini:"PrivateKey"`
+	PublicKey  string `ini:"PublicKey"`
+	Address    string `ini:"Address"`
 }
 
 type iniPeer struct {
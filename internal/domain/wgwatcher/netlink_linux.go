@@ -0,0 +1,362 @@
+package wgwatcher
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+type netlinkAttr struct {
+	Type uint16
+	Data []byte
+}
+
+type netlinkConn struct {
+	fd  int
+	seq uint32
+}
+
+// dialNetlink opens the generic netlink socket, the timeouts follow the deadline of the context.
+func dialNetlink(ctx context.Context) (*netlinkConn, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	timeout := netlinkTimeout
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < timeout {
+		timeout = time.Until(deadline)
+	}
+
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, syscall.NETLINK_GENERIC)
+	if err != nil {
+		return nil, fmt.Errorf("can't open socket: %w", err)
+	}
+
+	conn := &netlinkConn{
+		fd:  fd,
+		seq: uint32(time.Now().Unix()),
+	}
+
+	tv := syscall.NsecToTimeval(timeout.Nanoseconds())
+	for _, opt := range []int{syscall.SO_RCVTIMEO, syscall.SO_SNDTIMEO} {
+		if err = syscall.SetsockoptTimeval(fd, syscall.SOL_SOCKET, opt, &tv); err != nil {
+			conn.Close()
+
+			return nil, fmt.Errorf("can't set timeout: %w", err)
+		}
+	}
+
+	if err = syscall.Bind(fd, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}); err != nil {
+		conn.Close()
+
+		return nil, fmt.Errorf("can't bind socket: %w", err)
+	}
+
+	return conn, nil
+}
+
+func (c *netlinkConn) Close() {
+	_ = syscall.Close(c.fd)
+}
+
+// ResolveFamily returns the id of the generic netlink family, the family is missed if the module isn't loaded.
+func (c *netlinkConn) ResolveFamily(name string) (uint16, error) {
+	msgs, err := c.Execute(
+		genlIDCtrl,
+		syscall.NLM_F_REQUEST,
+		genlCtrlCmdGetFamily,
+		encodeNetlinkAttr(genlCtrlAttrFamName, encodeNetlinkString(name)),
+	)
+	if err != nil {
+		if errors.Is(err, syscall.ENOENT) {
+			return 0, fmt.Errorf("family %q not found, is the module loaded: %w", name, err)
+		}
+
+		return 0, fmt.Errorf("can't get family: %w", err)
+	}
+
+	for _, msg := range msgs {
+		if len(msg.Data) < genlHeaderLength {
+			continue
+		}
+
+		attrs, err := decodeNetlinkAttrs(msg.Data[genlHeaderLength:])
+		if err != nil {
+			return 0, fmt.Errorf("can't decode attrs: %w", err)
+		}
+
+		for _, attr := range attrs {
+			if attr.Type == genlCtrlAttrFamilyID {
+				return decodeNetlinkUint16(attr.Data), nil
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("family %q has no id", name)
+}
+
+// Execute sends the generic netlink request and collects the replies up to the end of the dump or the ack.
+func (c *netlinkConn) Execute(msgType, flags uint16, cmd uint8, payload []byte) ([]syscall.NetlinkMessage, error) {
+	c.seq++
+
+	genlHeader := []byte{cmd, wgGenlVersion, 0, 0}
+	if msgType == genlIDCtrl {
+		genlHeader[1] = genlCtrlVersion
+	}
+
+	req := make([]byte, syscall.NLMSG_HDRLEN, syscall.NLMSG_HDRLEN+len(genlHeader)+len(payload))
+	req = append(req, genlHeader...)
+	req = append(req, payload...)
+
+	nativeEndian.PutUint32(req[0:4], uint32(len(req)))
+	nativeEndian.PutUint16(req[4:6], msgType)
+	nativeEndian.PutUint16(req[6:8], flags)
+	nativeEndian.PutUint32(req[8:12], c.seq)
+
+	if err := syscall.Sendto(c.fd, req, 0, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}); err != nil {
+		return nil, fmt.Errorf("can't send: %w", err)
+	}
+
+	var replies []syscall.NetlinkMessage
+	for {
+		// The parsed messages point into the buffer, so every recv gets its own one.
+		buf := make([]byte, netlinkBufferSize)
+
+		n, _, err := syscall.Recvfrom(c.fd, buf, 0)
+		if err != nil {
+			return nil, fmt.Errorf("can't receive: %w", err)
+		}
+
+		msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			return nil, fmt.Errorf("can't parse: %w", err)
+		}
+
+		for _, msg := range msgs {
+			if msg.Header.Seq != c.seq {
+				continue
+			}
+
+			switch msg.Header.Type {
+			case syscall.NLMSG_DONE:
+				return replies, nil
+			case syscall.NLMSG_ERROR:
+				if len(msg.Data) < 4 {
+					return nil, errors.New("unexpected error length")
+				}
+
+				// The zero error is the ack.
+				if code := int32(nativeEndian.Uint32(msg.Data[0:4])); code != 0 {
+					return nil, syscall.Errno(-code)
+				}
+
+				return replies, nil
+			}
+
+			replies = append(replies, msg)
+
+			if msg.Header.Flags&syscall.NLM_F_MULTI == 0 && flags&syscall.NLM_F_ACK == 0 {
+				return replies, nil
+			}
+		}
+	}
+}
+
+func decodeNetlinkAttrs(b []byte) ([]netlinkAttr, error) {
+	var attrs []netlinkAttr
+	for len(b) >= syscall.NLA_HDRLEN {
+		length := int(nativeEndian.Uint16(b[0:2]))
+		if length < syscall.NLA_HDRLEN || length > len(b) {
+			return nil, fmt.Errorf("unexpected attr length: %d", length)
+		}
+
+		attrs = append(attrs, netlinkAttr{
+			Type: nativeEndian.Uint16(b[2:4]) &^ (syscall.NLA_F_NESTED | syscall.NLA_F_NET_BYTEORDER),
+			Data: b[syscall.NLA_HDRLEN:length],
+		})
+
+		// The padding of the last attr may be missed.
+		next := alignNetlinkAttr(length)
+		if next > len(b) {
+			next = len(b)
+		}
+		b = b[next:]
+	}
+
+	return attrs, nil
+}
+
+func encodeNetlinkAttr(attrType uint16, data []byte) []byte {
+	length := syscall.NLA_HDRLEN + len(data)
+
+	b := make([]byte, alignNetlinkAttr(length))
+	nativeEndian.PutUint16(b[0:2], uint16(length))
+	nativeEndian.PutUint16(b[2:4], attrType)
+	copy(b[syscall.NLA_HDRLEN:], data)
+
+	return b
+}
+
+func encodeNetlinkNestedAttr(attrType uint16, attrs ...[]byte) []byte {
+	var data []byte
+	for _, attr := range attrs {
+		data = append(data, attr...)
+	}
+
+	return encodeNetlinkAttr(attrType|syscall.NLA_F_NESTED, data)
+}
+
+func alignNetlinkAttr(length int) int {
+	return (length + syscall.NLA_ALIGNTO - 1) &^ (syscall.NLA_ALIGNTO - 1)
+}
+
+func encodeNetlinkString(s string) []byte {
+	return append([]byte(s), 0)
+}
+
+func encodeNetlinkUint16(v uint16) []byte {
+	b := make([]byte, 2)
+	nativeEndian.PutUint16(b, v)
+
+	return b
+}
+
+func encodeNetlinkUint32(v uint32) []byte {
+	b := make([]byte, 4)
+	nativeEndian.PutUint32(b, v)
+
+	return b
+}
+
+func decodeNetlinkUint16(b []byte) uint16 {
+	if len(b) < 2 {
+		return 0
+	}
+
+	return nativeEndian.Uint16(b)
+}
+
+func decodeNetlinkUint32(b []byte) uint32 {
+	if len(b) < 4 {
+		return 0
+	}
+
+	return nativeEndian.Uint32(b)
+}
+
+func decodeNetlinkUint64(b []byte) uint64 {
+	if len(b) < 8 {
+		return 0
+	}
+
+	return nativeEndian.Uint64(b)
+}
+
+// decodeSockaddr formats the sockaddr_in or sockaddr_in6 the way the dump does.
+func decodeSockaddr(b []byte) (string, error) {
+	if len(b) < 2 {
+		return "", fmt.Errorf("unexpected length: %d", len(b))
+	}
+
+	switch family := nativeEndian.Uint16(b[0:2]); family {
+	case syscall.AF_INET:
+		if len(b) < syscall.SizeofSockaddrInet4 {
+			return "", fmt.Errorf("unexpected length: %d", len(b))
+		}
+
+		port := binary.BigEndian.Uint16(b[2:4])
+
+		return net.JoinHostPort(net.IP(b[4:8]).String(), strconv.Itoa(int(port))), nil
+	case syscall.AF_INET6:
+		if len(b) < syscall.SizeofSockaddrInet6 {
+			return "", fmt.Errorf("unexpected length: %d", len(b))
+		}
+
+		port := binary.BigEndian.Uint16(b[2:4])
+
+		return net.JoinHostPort(net.IP(b[8:24]).String(), strconv.Itoa(int(port))), nil
+	case 0:
+		return "", nil
+	default:
+		return "", fmt.Errorf("unexpected family: %d", family)
+	}
+}
+
+func decodeAllowedIPs(b []byte) ([]string, error) {
+	ipAttrs, err := decodeNetlinkAttrs(b)
+	if err != nil {
+		return nil, fmt.Errorf("can't decode allowed ip list: %w", err)
+	}
+
+	allowedIPs := make([]string, 0, len(ipAttrs))
+	for _, ipAttr := range ipAttrs {
+		attrs, err := decodeNetlinkAttrs(ipAttr.Data)
+		if err != nil {
+			return nil, fmt.Errorf("can't decode allowed ip: %w", err)
+		}
+
+		var (
+			ip   net.IP
+			mask uint8
+		)
+		for _, attr := range attrs {
+			switch attr.Type {
+			case wgAllowedIPAttrIPAddr:
+				ip = net.IP(attr.Data)
+			case wgAllowedIPAttrCIDRMask:
+				if len(attr.Data) != 0 {
+					mask = attr.Data[0]
+				}
+			}
+		}
+
+		allowedIPs = append(allowedIPs, fmt.Sprintf("%s/%d", ip, mask))
+	}
+
+	return allowedIPs, nil
+}
+
+// encodeAllowedIPs encodes the allowed ips in the format of the dump, e.g. 10.0.0.2/32,fd00::2/128.
+func encodeAllowedIPs(rawAllowedIPs string) ([][]byte, error) {
+	var attrs [][]byte
+	for _, rawAllowedIP := range strings.Split(rawAllowedIPs, ",") {
+		_, ipNet, err := net.ParseCIDR(strings.TrimSpace(rawAllowedIP))
+		if err != nil {
+			return nil, fmt.Errorf("can't parse %q: %w", rawAllowedIP, err)
+		}
+
+		var (
+			family = uint16(syscall.AF_INET6)
+			ip     = ipNet.IP.To16()
+		)
+		if ip4 := ipNet.IP.To4(); ip4 != nil {
+			family, ip = syscall.AF_INET, ip4
+		}
+
+		ones, _ := ipNet.Mask.Size()
+
+		attrs = append(attrs, encodeNetlinkNestedAttr(
+			0,
+			encodeNetlinkAttr(wgAllowedIPAttrFamily, encodeNetlinkUint16(family)),
+			encodeNetlinkAttr(wgAllowedIPAttrIPAddr, ip),
+			encodeNetlinkAttr(wgAllowedIPAttrCIDRMask, []byte{uint8(ones)}),
+		))
+	}
+
+	return attrs, nil
+}
+
+func parseUint16(raw string) (uint16, error) {
+	v, err := strconv.ParseUint(raw, 10, 16)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint16(v), nil
+}
@@ -0,0 +1,174 @@
+package wgwatcher
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"syscall"
+	"testing"
+)
+
+func TestNetlinkAttrRoundTrip(t *testing.T) {
+	var b []byte
+	b = append(b, encodeNetlinkAttr(genlCtrlAttrFamName, encodeNetlinkString("wireguard"))...)
+	b = append(b, encodeNetlinkAttr(genlCtrlAttrFamilyID, encodeNetlinkUint16(27))...)
+	b = append(b, encodeNetlinkNestedAttr(3, encodeNetlinkAttr(1, encodeNetlinkUint32(42)))...)
+
+	if len(b)%syscall.NLA_ALIGNTO != 0 {
+		t.Fatalf("encoded length %d isn't aligned", len(b))
+	}
+
+	attrs, err := decodeNetlinkAttrs(b)
+	if err != nil {
+		t.Fatalf("decodeNetlinkAttrs() error = %v", err)
+	}
+
+	if len(attrs) != 3 {
+		t.Fatalf("decodeNetlinkAttrs() got %d attrs, want 3", len(attrs))
+	}
+
+	if attrs[0].Type != genlCtrlAttrFamName || !bytes.Equal(attrs[0].Data, []byte("wireguard\x00")) {
+		t.Errorf("family name attr = %+v", attrs[0])
+	}
+
+	if attrs[1].Type != genlCtrlAttrFamilyID || decodeNetlinkUint16(attrs[1].Data) != 27 {
+		t.Errorf("family id attr = %+v", attrs[1])
+	}
+
+	// The nested flag is dropped from the type.
+	if attrs[2].Type != 3 {
+		t.Errorf("nested attr type = %d, want 3", attrs[2].Type)
+	}
+
+	nested, err := decodeNetlinkAttrs(attrs[2].Data)
+	if err != nil {
+		t.Fatalf("decodeNetlinkAttrs() of nested error = %v", err)
+	}
+
+	if len(nested) != 1 || nested[0].Type != 1 || decodeNetlinkUint32(nested[0].Data) != 42 {
+		t.Errorf("nested attrs = %+v", nested)
+	}
+}
+
+func TestDecodeNetlinkAttrs(t *testing.T) {
+	unpadded := encodeNetlinkAttr(1, []byte{7})[:syscall.NLA_HDRLEN+1]
+
+	tests := []struct {
+		name    string
+		raw     []byte
+		want    []netlinkAttr
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			raw:  nil,
+		},
+		{
+			name: "last attr unpadded",
+			raw:  unpadded,
+			want: []netlinkAttr{{Type: 1, Data: []byte{7}}},
+		},
+		{
+			name:    "length over buffer",
+			raw:     []byte{16, 0, 1, 0, 0, 0, 0, 0},
+			wantErr: true,
+		},
+		{
+			name:    "length under header",
+			raw:     []byte{2, 0, 1, 0},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeNetlinkAttrs(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("decodeNetlinkAttrs() = %+v, want error", got)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("decodeNetlinkAttrs() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeNetlinkAttrs() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllowedIPsRoundTrip(t *testing.T) {
+	attrs, err := encodeAllowedIPs("10.8.0.4/32, fd00::2/128,192.168.1.7/24")
+	if err != nil {
+		t.Fatalf("encodeAllowedIPs() error = %v", err)
+	}
+
+	got, err := decodeAllowedIPs(bytes.Join(attrs, nil))
+	if err != nil {
+		t.Fatalf("decodeAllowedIPs() error = %v", err)
+	}
+
+	// The host bits are masked the way the kernel does.
+	want := []string{"10.8.0.4/32", "fd00::2/128", "192.168.1.0/24"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decodeAllowedIPs() = %v, want %v", got, want)
+	}
+
+	if _, err = encodeAllowedIPs("10.8.0.4"); err == nil {
+		t.Error("encodeAllowedIPs() of the address without mask, want error")
+	}
+}
+
+func TestDecodeSockaddr(t *testing.T) {
+	inet4 := make([]byte, syscall.SizeofSockaddrInet4)
+	nativeEndian.PutUint16(inet4[0:2], syscall.AF_INET)
+	binary.BigEndian.PutUint16(inet4[2:4], 51820)
+	copy(inet4[4:8], []byte{192, 0, 2, 10})
+
+	inet6 := make([]byte, syscall.SizeofSockaddrInet6)
+	nativeEndian.PutUint16(inet6[0:2], syscall.AF_INET6)
+	binary.BigEndian.PutUint16(inet6[2:4], 51000)
+	copy(inet6[8:24], []byte{0x20, 0x01, 0x0d, 0xb8, 15: 1})
+
+	unknown := make([]byte, syscall.SizeofSockaddrInet4)
+	nativeEndian.PutUint16(unknown[0:2], syscall.AF_UNIX)
+
+	tests := []struct {
+		name    string
+		raw     []byte
+		want    string
+		wantErr bool
+	}{
+		{name: "inet4", raw: inet4, want: "192.0.2.10:51820"},
+		{name: "inet6", raw: inet6, want: "[2001:db8::1]:51000"},
+		{name: "unset", raw: make([]byte, syscall.SizeofSockaddrInet4), want: ""},
+		{name: "short inet4", raw: inet4[:6], wantErr: true},
+		{name: "unknown family", raw: unknown, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeSockaddr(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("decodeSockaddr() = %q, want error", got)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("decodeSockaddr() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("decodeSockaddr() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -5,79 +5,157 @@ import (
 	"fmt"
 	"io/fs"
 	"os"
-	"os/exec"
 	"path"
 	"sort"
 	"strconv"
 	"strings"
-	"unicode"
+	"time"
 
+	"go.uber.org/zap"
 	"gopkg.in/ini.v1"
+
+	"github.com/whiteforestz/iino/internal/pkg/eventbus"
+	"github.com/whiteforestz/iino/internal/pkg/logger"
+)
+
+const (
+	unmanagedPeerNameLength = 8
 )
 
 func (d *Domain) updateUsage(ctx context.Context) error {
-	usage, err := d.getCurrentUsagePeer(ctx)
+	now := time.Now()
+
+	devices, err := d.backend.GetDevices(ctx)
+	if err != nil {
+		// The interfaces are flagged down, so the failure isn't an error of the update itself.
+		d.setInterfacesDown(err)
+		d.publishCollectorFailed(collectorBackend, err)
+
+		return nil
+	}
+
+	current, err := d.getCurrentUsage(devices)
 	if err != nil {
 		return fmt.Errorf("can't get current usage: %w", err)
 	}
 
-	enrichedUsage, err := d.enrichUsagePeer(usage)
+	d.warnUnmanagedPeers(current.Peer)
+
+	enrichedUsage, err := d.enrichUsagePeer(current.Peer)
 	if err != nil {
 		return fmt.Errorf("can't enrich usage: %w", err)
 	}
 
-	err = d.saveSnapshotPeerAccessor(castUsagePeerToSnapshotPeerAccessor(enrichedUsage))
+	enrichedUsage = d.enrichUsagePeerRate(enrichedUsage, now)
+	enrichedUsage = d.enrichUsagePeerEndpoint(enrichedUsage, now)
+
+	d.publishPeerStateChanges(enrichedUsage, now)
+
+	snapshotPeerAccessor := mergeSnapshotPeerAccessor(d.snapshotPeerAccessor, enrichedUsage)
+
+	err = d.saveSnapshotPeerAccessor(snapshotPeerAccessor)
 	if err != nil {
 		return fmt.Errorf("can't flush usage: %w", err)
 	}
 
+	d.snapshotPeerAccessor = snapshotPeerAccessor
+
+	ifaceUsage := d.groupUsageByInterface(current.Interface, enrichedUsage)
+
 	d.mux.Lock()
-	defer d.mux.Unlock()
+	d.usage.Interface = ifaceUsage
+	d.peerSpecAccessor = current.PeerSpec
+	d.mux.Unlock()
 
-	d.usage.Peer = enrichedUsage
+	d.events.Publish(eventbus.SampleTaken{
+		Source: eventSource,
+		At:     now,
+	})
 
 	return nil
 }
 
-func (d *Domain) getCurrentUsagePeer(ctx context.Context) ([]Peer, error) {
-	peerNameAccessor, err := d.getPeerNameAccessor()
+func (d *Domain) getCurrentUsage(devices []device) (*currentUsage, error) {
+	nameAccessor, err := d.getPeerNameAccessor()
 	if err != nil {
 		return nil, fmt.Errorf("can't get peer accessor: %w", err)
 	}
 
-	raw, err := exec.CommandContext(ctx, d.cfg.Cmd, d.cfg.CmdArgs...).Output()
-	if err != nil {
-		return nil, fmt.Errorf("can't exec cmd: %w", err)
-	}
-
-	lines := strings.Split(string(raw), "\n")
-	if len(lines) == 0 {
-		return nil, fmt.Errorf("unexpected lines count: %d", len(lines))
+	current := currentUsage{
+		Interface: make(map[string]InterfaceUsage, len(devices)),
+		PeerSpec:  make(map[string]PeerSpec),
 	}
+	for _, dev := range devices {
+		ifaceNameAccessor, found := nameAccessor[dev.Name]
+		if !found {
+			continue
+		}
 
-	usage := make([]Peer, 0, len(lines[1:]))
-	for _, peerLine := range lines[1 : len(lines)-1] {
-		p, err := extractPeerData(peerLine, peerNameAccessor)
-		if err != nil {
-			return nil, fmt.Errorf("can't extract peed data: %w", err)
+		current.Interface[dev.Name] = InterfaceUsage{
+			Name:         dev.Name,
+			PublicKey:    dev.PublicKey,
+			ListenPort:   dev.ListenPort,
+			FirewallMark: dev.FirewallMark,
+			PeerCount:    len(dev.Peer),
 		}
 
-		usage = append(usage, *p)
+		for _, devPeer := range dev.Peer {
+			p, spec := castDevicePeer(devPeer, ifaceNameAccessor)
+
+			p.Interface = dev.Name
+			spec.Interface = dev.Name
+
+			current.Peer = append(current.Peer, *p)
+
+			// The unmanaged peers are reported only, they can't be controlled by their truncated keys.
+			if !p.Unmanaged {
+				current.PeerSpec[p.Name] = *spec
+			}
+		}
 	}
 
-	sort.SliceStable(usage, func(i, j int) bool {
-		return usage[i].LatestHandshakeUnix > usage[j].LatestHandshakeUnix
+	sort.SliceStable(current.Peer, func(i, j int) bool {
+		return current.Peer[i].LatestHandshakeUnix > current.Peer[j].LatestHandshakeUnix
 	})
 
-	return usage, nil
+	return &current, nil
 }
 
-func (d *Domain) getPeerNameAccessor() (map[string]string, error) {
+// getPeerNameAccessor returns peer name accessors for every watched interface.
+// The names are used as peer identifiers, so they have to be unique across the interfaces.
+func (d *Domain) getPeerNameAccessor() (map[string]peerNameAccessor, error) {
 	var (
-		accessor = make(map[string]string)
+		accessor = make(map[string]peerNameAccessor, len(d.cfg.InterfaceConfs))
+		seen     = make(map[string]string)
 	)
 
-	err := fs.WalkDir(os.DirFS(d.cfg.ConfDirPath), ".", func(ep string, e fs.DirEntry, err error) error {
+	for _, ifaceConf := range d.cfg.InterfaceConfs {
+		ifaceAccessor, err := getInterfacePeerNameAccessor(ifaceConf)
+		if err != nil {
+			return nil, fmt.Errorf("can't get peer accessor of %q: %w", ifaceConf.Name, err)
+		}
+
+		for _, name := range ifaceAccessor.names() {
+			if iface, found := seen[name]; found {
+				return nil, fmt.Errorf("peer %q is duplicated at %q and %q", name, iface, ifaceConf.Name)
+			}
+
+			seen[name] = ifaceConf.Name
+		}
+
+		accessor[ifaceConf.Name] = ifaceAccessor
+	}
+
+	return accessor, nil
+}
+
+func getInterfacePeerNameAccessor(ifaceConf InterfaceConf) (peerNameAccessor, error) {
+	accessor := peerNameAccessor{
+		ByPublicKey:    make(map[string]string),
+		ByPresharedKey: make(map[string]string),
+	}
+
+	err := fs.WalkDir(os.DirFS(ifaceConf.ConfDirPath), ".", func(ep string, e fs.DirEntry, err error) error {
 		if err != nil {
 			return fmt.Errorf("fs error at %q: %w", ep, err)
 		}
@@ -86,22 +164,39 @@ func (d *Domain) getPeerNameAccessor() (map[string]string, error) {
 			return nil
 		}
 
-		match := d.cfg.ConfPatternRe.FindSubmatch([]byte(e.Name()))
+		// The conf dir may be shared by many interfaces, so foreign confs are skipped.
+		match := ifaceConf.ConfPatternRe.FindSubmatch([]byte(e.Name()))
 		if len(match) != 2 {
-			return fmt.Errorf("unexpected conf name: %q", e.Name())
+			return nil
 		}
 
 		var conf iniConf
-		if err = ini.MapTo(&conf, path.Join(d.cfg.ConfDirPath, ep)); err != nil {
+		if err = ini.MapTo(&conf, path.Join(ifaceConf.ConfDirPath, ep)); err != nil {
 			return fmt.Errorf("can't map conf at %q: %w", ep, err)
 		}
 
-		accessor[conf.Peer.PresharedKey] = string(match[1])
+		name := string(match[1])
+
+		publicKey := conf.Interface.PublicKey
+		if conf.Interface.PrivateKey != "" {
+			publicKey, err = derivePublicKey(conf.Interface.PrivateKey)
+			if err != nil {
+				return fmt.Errorf("can't derive public key of %q: %w", ep, err)
+			}
+		}
+
+		if publicKey != "" {
+			accessor.ByPublicKey[publicKey] = name
+		}
+
+		if conf.Peer.PresharedKey != "" {
+			accessor.ByPresharedKey[conf.Peer.PresharedKey] = name
+		}
 
 		return nil
 	})
 	if err != nil {
-		return nil, fmt.Errorf("can't walk dir at %q: %w", d.cfg.ConfDirPath, err)
+		return peerNameAccessor{}, fmt.Errorf("can't walk dir at %q: %w", ifaceConf.ConfDirPath, err)
 	}
 
 	return accessor, nil
@@ -110,13 +205,23 @@ func (d *Domain) getPeerNameAccessor() (map[string]string, error) {
 func (d *Domain) enrichUsagePeer(usage []Peer) ([]Peer, error) {
 	enrichedUsage := make([]Peer, 0, len(usage))
 	for _, peer := range usage {
-		snapshot, found := d.snapshotPeerAccessor[peer.Name]
+		var (
+			snapshot snapshotPeer
+			found    bool
+		)
+		if !peer.Unmanaged {
+			snapshot, found = d.snapshotPeerAccessor[peer.Name]
+		}
+
 		if found {
 			if peer.LatestHandshakeUnix < snapshot.LatestHandshakeUnix {
 				peer.LatestHandshakeUnix = snapshot.LatestHandshakeUnix
 			}
 		}
 
+		peer.TransferRxTotal = accumulateTransfer(snapshot.TransferRxTotal, snapshot.TransferRxLast, peer.TransferRx)
+		peer.TransferTxTotal = accumulateTransfer(snapshot.TransferTxTotal, snapshot.TransferTxLast, peer.TransferTx)
+
 		enrichedUsage = append(enrichedUsage, peer)
 	}
 
@@ -127,41 +232,128 @@ func (d *Domain) enrichUsagePeer(usage []Peer) ([]Peer, error) {
 	return enrichedUsage, nil
 }
 
-func extractPeerData(peerLine string, peerNameAccessor map[string]string) (*Peer, error) {
-	tokens := strings.FieldsFunc(peerLine, func(r rune) bool {
-		return unicode.IsSpace(r)
-	})
-	if len(tokens) != 8 {
-		return nil, fmt.Errorf("unexpected line content length: %d", len(tokens))
+// find looks the peer up by the public key and falls back to the preshared key, if the peer has one.
+func (a peerNameAccessor) find(publicKey, presharedKey string) (string, bool) {
+	if name, found := a.ByPublicKey[publicKey]; found {
+		return name, true
 	}
 
-	presharedKey, rawLatestHandshake := tokens[1], tokens[4]
-	rawRx, rawTx := tokens[5], tokens[6]
+	if presharedKey == dumpValueNone {
+		return "", false
+	}
 
-	name, found := peerNameAccessor[presharedKey]
-	if !found {
-		return nil, fmt.Errorf("unknown peer with preshared key %q", presharedKey)
+	name, found := a.ByPresharedKey[presharedKey]
+
+	return name, found
+}
+
+// names returns every name of the accessor once.
+func (a peerNameAccessor) names() []string {
+	seen := make(map[string]struct{}, len(a.ByPublicKey)+len(a.ByPresharedKey))
+	for _, names := range []map[string]string{a.ByPublicKey, a.ByPresharedKey} {
+		for _, name := range names {
+			seen[name] = struct{}{}
+		}
 	}
 
-	latestHandshakeUnix, err := strconv.ParseInt(rawLatestHandshake, 10, 64)
-	if err != nil {
-		return nil, fmt.Errorf("unexpected time format: %w", err)
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
 	}
 
-	rx, err := strconv.ParseInt(rawRx, 10, 64)
-	if err != nil {
-		return nil, fmt.Errorf("unexpected byte format: %w", err)
+	return names
+}
+
+// trackKey is the key of the peer in the trackers kept in memory. The unmanaged peers are keyed
+// by the interface too, the truncated keys may match the names of the managed peers otherwise,
+// and the names taken from the conf file names never have a slash.
+func trackKey(peer Peer) string {
+	if !peer.Unmanaged {
+		return peer.Name
 	}
 
-	tx, err := strconv.ParseInt(rawTx, 10, 64)
-	if err != nil {
-		return nil, fmt.Errorf("unexpected byte format: %w", err)
+	return peer.Interface + "/" + peer.Name
+}
+
+// unmanagedPeerName is the placeholder name of the peer without conf, it's the truncated public key.
+func unmanagedPeerName(publicKey string) string {
+	if len(publicKey) <= unmanagedPeerNameLength {
+		return publicKey
+	}
+
+	return publicKey[:unmanagedPeerNameLength]
+}
+
+// warnUnmanagedPeers logs the unmanaged peers once they appear rather than on every tick.
+func (d *Domain) warnUnmanagedPeers(usage []Peer) {
+	unmanagedPeerAccessor := make(map[string]struct{})
+	for _, peer := range usage {
+		if !peer.Unmanaged {
+			continue
+		}
+
+		if _, found := d.unmanagedPeerAccessor[peer.Name]; !found {
+			logger.Instance().Warn(
+				"unmanaged peer found",
+				zap.String("interface", peer.Interface),
+				zap.String("peer", peer.Name),
+			)
+		}
+
+		unmanagedPeerAccessor[peer.Name] = struct{}{}
+	}
+
+	d.unmanagedPeerAccessor = unmanagedPeerAccessor
+}
+
+// accumulateTransfer adds the counter growth since the last snapshot to the lifetime total.
+// The counter less than the last one means the interface has been restarted and the counter
+// started from zero, so the whole counter is the growth.
+func accumulateTransfer(total, last, current int64) int64 {
+	if current < last {
+		return total + current
+	}
+
+	return total + current - last
+}
+
+// castDevicePeer names the peer of the device, the spec keeps the values in the format of the dump.
+func castDevicePeer(devPeer devicePeer, nameAccessor peerNameAccessor) (*Peer, *PeerSpec) {
+	presharedKey := devPeer.PresharedKey
+	if presharedKey == "" {
+		presharedKey = dumpValueNone
+	}
+
+	// The peers without confs are still reported, so the stray ones are noticed.
+	name, found := nameAccessor.find(devPeer.PublicKey, presharedKey)
+	if !found {
+		name = unmanagedPeerName(devPeer.PublicKey)
+	}
+
+	allowedIPs := dumpValueNone
+	if len(devPeer.AllowedIPs) != 0 {
+		allowedIPs = strings.Join(devPeer.AllowedIPs, ",")
+	}
+
+	persistentKeepalive := dumpValueOff
+	if devPeer.PersistentKeepalive != 0 {
+		persistentKeepalive = strconv.FormatInt(int64(devPeer.PersistentKeepalive/time.Second), 10)
 	}
 
 	return &Peer{
 		Name:                name,
-		LatestHandshakeUnix: latestHandshakeUnix,
-		TransferRx:          rx,
-		TransferTx:          tx,
-	}, nil
+		Unmanaged:           !found,
+		LatestHandshakeUnix: devPeer.LatestHandshakeUnix,
+		TransferRx:          devPeer.TransferRx,
+		TransferTx:          devPeer.TransferTx,
+		Endpoint:            devPeer.Endpoint,
+		AllowedIPs:          devPeer.AllowedIPs,
+		PersistentKeepalive: devPeer.PersistentKeepalive,
+	}, &PeerSpec{
+		Name:                name,
+		PublicKey:           devPeer.PublicKey,
+		PresharedKey:        presharedKey,
+		AllowedIPs:          allowedIPs,
+		PersistentKeepalive: persistentKeepalive,
+	}
 }
@@ -0,0 +1,72 @@
+package wgwatcher
+
+import (
+	"time"
+)
+
+type peerTransferSample struct {
+	At         time.Time
+	TransferRx int64
+	TransferTx int64
+}
+
+// enrichUsagePeerRate calculates transfer rates over the latest tick and over the rate window.
+func (d *Domain) enrichUsagePeerRate(usage []Peer, at time.Time) []Peer {
+	var (
+		windowStart          = at.Add(-d.cfg.RateWindow)
+		transferSamplesAfter = make(map[string][]peerTransferSample, len(usage))
+	)
+
+	enrichedUsage := make([]Peer, 0, len(usage))
+	for _, peer := range usage {
+		sample := peerTransferSample{
+			At:         at,
+			TransferRx: peer.TransferRx,
+			TransferTx: peer.TransferTx,
+		}
+
+		key := trackKey(peer)
+
+		samples := d.transferSamples[key]
+		if len(samples) != 0 {
+			var (
+				latest = samples[len(samples)-1]
+				oldest = samples[0]
+			)
+
+			peer.TransferRxRate, peer.TransferTxRate = calcTransferRate(latest, sample)
+			peer.TransferRxRateAvg, peer.TransferTxRateAvg = calcTransferRate(oldest, sample)
+		}
+
+		samples = append(samples, sample)
+		for len(samples) > 1 && samples[0].At.Before(windowStart) {
+			samples = samples[1:]
+		}
+		transferSamplesAfter[key] = samples
+
+		enrichedUsage = append(enrichedUsage, peer)
+	}
+
+	// Peers gone from the dump are dropped along with their samples.
+	d.transferSamples = transferSamplesAfter
+
+	return enrichedUsage
+}
+
+func calcTransferRate(from, to peerTransferSample) (int64, int64) {
+	elapsed := to.At.Sub(from.At).Seconds()
+	if elapsed <= 0 {
+		return 0, 0
+	}
+
+	perSecond := func(current, last int64) int64 {
+		// The counters are reset along with the interface.
+		if current < last {
+			return 0
+		}
+
+		return int64(float64(current-last) / elapsed)
+	}
+
+	return perSecond(to.TransferRx, from.TransferRx), perSecond(to.TransferTx, from.TransferTx)
+}
@@ -15,6 +15,18 @@ const (
 
 type snapshotPeer struct {
 	LatestHandshakeUnix int64 `json:"latestHandshakeUnix,omitempty"`
+	TransferRxTotal     int64 `json:"transferRxTotal,omitempty"`
+	TransferTxTotal     int64 `json:"transferTxTotal,omitempty"`
+	TransferRxLast      int64 `json:"transferRxLast,omitempty"`
+	TransferTxLast      int64 `json:"transferTxLast,omitempty"`
+
+	EndpointHistory []snapshotEndpointChange `json:"endpointHistory,omitempty"`
+	RoamCount       int64                    `json:"roamCount,omitempty"`
+}
+
+type snapshotEndpointChange struct {
+	Endpoint      string `json:"endpoint"`
+	ChangedAtUnix int64  `json:"changedAtUnix"`
 }
 
 func (d *Domain) saveSnapshotPeerAccessor(accessor map[string]snapshotPeer) error {
@@ -48,11 +60,36 @@ func (d *Domain) loadSnapshotPeerAccessor() (map[string]snapshotPeer, error) {
 	return accessor, nil
 }
 
-func castUsagePeerToSnapshotPeerAccessor(usage []Peer) map[string]snapshotPeer {
-	accessor := make(map[string]snapshotPeer, len(usage))
+// mergeSnapshotPeerAccessor keeps snapshots of peers missing in the usage, so their totals survive
+// a temporary removal from the interface.
+func mergeSnapshotPeerAccessor(last map[string]snapshotPeer, usage []Peer) map[string]snapshotPeer {
+	accessor := make(map[string]snapshotPeer, len(last)+len(usage))
+	for name, snapshot := range last {
+		accessor[name] = snapshot
+	}
+
 	for _, peer := range usage {
+		// The unmanaged peers are left out, they'd pile up in the snapshot under their truncated keys.
+		if peer.Unmanaged {
+			continue
+		}
+
+		endpointHistory := make([]snapshotEndpointChange, 0, len(peer.EndpointHistory))
+		for _, change := range peer.EndpointHistory {
+			endpointHistory = append(endpointHistory, snapshotEndpointChange{
+				Endpoint:      change.Endpoint,
+				ChangedAtUnix: change.ChangedAtUnix,
+			})
+		}
+
 		accessor[peer.Name] = snapshotPeer{
 			LatestHandshakeUnix: peer.LatestHandshakeUnix,
+			TransferRxTotal:     peer.TransferRxTotal,
+			TransferTxTotal:     peer.TransferTxTotal,
+			TransferRxLast:      peer.TransferRx,
+			TransferTxLast:      peer.TransferTx,
+			EndpointHistory:     endpointHistory,
+			RoamCount:           peer.RoamCount,
 		}
 	}
 
@@ -0,0 +1,133 @@
+// Package eventbus is the in-process publish/subscribe of typed events. Publishing never blocks,
+// every subscriber has a bounded buffer and misses the events while it's full.
+package eventbus
+
+import (
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/whiteforestz/iino/internal/pkg/logger"
+)
+
+type Bus struct {
+	mux         *sync.RWMutex
+	nextID      uint64
+	subscribers map[uint64]subscriber
+}
+
+type subscriber interface {
+	deliver(event interface{})
+	close()
+}
+
+func New() *Bus {
+	return &Bus{
+		mux:         &sync.RWMutex{},
+		subscribers: make(map[uint64]subscriber),
+	}
+}
+
+// Publish hands the event to every subscriber of its type.
+func (b *Bus) Publish(event interface{}) {
+	b.mux.RLock()
+	defer b.mux.RUnlock()
+
+	for _, s := range b.subscribers {
+		s.deliver(event)
+	}
+}
+
+// Subscription receives the events of the type T, T may be an interface to receive many types at once.
+type Subscription[T any] struct {
+	id   uint64
+	name string
+	bus  *Bus
+	ch   chan T
+
+	mux     *sync.Mutex
+	dropped uint64
+	lagging bool
+}
+
+// Subscribe subscribes to the events of the type T, the name identifies the subscriber in the logs.
+func Subscribe[T any](b *Bus, name string, bufferSize int) *Subscription[T] {
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	b.nextID++
+
+	s := &Subscription[T]{
+		id:   b.nextID,
+		name: name,
+		bus:  b,
+		ch:   make(chan T, bufferSize),
+		mux:  &sync.Mutex{},
+	}
+	b.subscribers[s.id] = s
+
+	return s
+}
+
+// Events returns the channel of the events, it's closed along with the subscription.
+func (s *Subscription[T]) Events() <-chan T {
+	return s.ch
+}
+
+// Dropped returns how many events are missed because of the full buffer.
+func (s *Subscription[T]) Dropped() uint64 {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return s.dropped
+}
+
+// Close unsubscribes, the events published afterwards aren't received.
+func (s *Subscription[T]) Close() {
+	s.bus.mux.Lock()
+	defer s.bus.mux.Unlock()
+
+	if _, found := s.bus.subscribers[s.id]; !found {
+		return
+	}
+
+	delete(s.bus.subscribers, s.id)
+	s.close()
+}
+
+func (s *Subscription[T]) deliver(event interface{}) {
+	typed, ok := event.(T)
+	if !ok {
+		return
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	select {
+	case s.ch <- typed:
+		s.lagging = false
+	default:
+		s.dropped++
+
+		// The lagging subscriber is reported once until it catches up, otherwise every event is logged.
+		if !s.lagging {
+			s.lagging = true
+
+			logger.Instance().Warn(
+				"subscriber is lagging, events are dropped",
+				zap.String("subscriber", s.name),
+				zap.String("event", fmt.Sprintf("%T", event)),
+			)
+		}
+	}
+}
+
+func (s *Subscription[T]) close() {
+	close(s.ch)
+}
@@ -0,0 +1,17 @@
+package eventbus
+
+import "time"
+
+// SampleTaken is published by the watcher once all its collectors are run.
+type SampleTaken struct {
+	Source string
+	At     time.Time
+}
+
+// CollectorFailed is published by the watcher on every failed run of the collector.
+type CollectorFailed struct {
+	Source    string
+	Collector string
+	Err       error
+	At        time.Time
+}
@@ -0,0 +1,64 @@
+// Package humanize formats the values for the messages to the admin.
+package humanize
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	bytesThreshold = 1000
+)
+
+var (
+	bytesUnitSlugs = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB", "ZiB", "YiB"}
+
+	durationUnits = []struct {
+		slug string
+		d    time.Duration
+	}{
+		{slug: "d", d: 24 * time.Hour},
+		{slug: "h", d: time.Hour},
+		{slug: "m", d: time.Minute},
+		{slug: "s", d: time.Second},
+	}
+)
+
+// Bytes formats the size in the binary units, the unit is switched at 1000, so the number has 3 digits at most.
+func Bytes(bytes int64) string {
+	var (
+		order int
+		n     = float64(bytes)
+	)
+
+	for n > bytesThreshold && order < len(bytesUnitSlugs)-1 {
+		n /= 1024
+		order++
+	}
+
+	return fmt.Sprintf("%.2f %s", n, bytesUnitSlugs[order])
+}
+
+// Duration formats the duration dropping the zero units, e.g. 3d 4h 5m or 1m 30s,
+// the seconds are dropped from an hour on.
+func Duration(d time.Duration) string {
+	if d >= time.Hour {
+		d = d.Truncate(time.Minute)
+	} else {
+		d = d.Truncate(time.Second)
+	}
+
+	var parts []string
+	for _, unit := range durationUnits {
+		if n := d / unit.d; n != 0 {
+			parts = append(parts, fmt.Sprintf("%d%s", n, unit.slug))
+			d -= n * unit.d
+		}
+	}
+	if len(parts) == 0 {
+		return "0s"
+	}
+
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,46 @@
+package humanize
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBytes(t *testing.T) {
+	tests := []struct {
+		bytes int64
+		want  string
+	}{
+		{bytes: 0, want: "0.00 B"},
+		{bytes: 1000, want: "1000.00 B"},
+		{bytes: 1010, want: "0.99 KiB"},
+		{bytes: 1536 * 1024, want: "1.50 MiB"},
+		{bytes: 5 << 40, want: "5.00 TiB"},
+	}
+
+	for _, tt := range tests {
+		if got := Bytes(tt.bytes); got != tt.want {
+			t.Errorf("Bytes(%d) = %q, want %q", tt.bytes, got, tt.want)
+		}
+	}
+}
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 0, want: "0s"},
+		{d: 1500 * time.Millisecond, want: "1s"},
+		{d: 90 * time.Second, want: "1m 30s"},
+		{d: 5 * time.Minute, want: "5m"},
+		{d: time.Hour + 59*time.Second, want: "1h"},
+		{d: 75*time.Hour + 5*time.Minute + 12*time.Second, want: "3d 3h 5m"},
+		{d: 24*time.Hour + 5*time.Minute, want: "1d 5m"},
+	}
+
+	for _, tt := range tests {
+		if got := Duration(tt.d); got != tt.want {
+			t.Errorf("Duration(%s) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
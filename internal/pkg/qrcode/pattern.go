@@ -0,0 +1,309 @@
+package qrcode
+
+const (
+	formatGenerator  = 0x537
+	formatMask       = 0x5412
+	versionGenerator = 0x1F25
+
+	penaltyRun     = 3
+	penaltyBlock   = 3
+	penaltyFinder  = 40
+	penaltyBalance = 10
+)
+
+var (
+	finderLikePatterns = [][]bool{
+		{true, false, true, true, true, false, true, false, false, false, false},
+		{false, false, false, false, true, false, true, true, true, false, true},
+	}
+)
+
+func (c *Code) setFunctionModule(x, y int, dark bool) {
+	c.modules[y][x] = dark
+	c.isFunction[y][x] = true
+}
+
+func (c *Code) drawFunctionPatterns() {
+	for i := 0; i < c.Size; i++ {
+		c.setFunctionModule(6, i, i%2 == 0)
+		c.setFunctionModule(i, 6, i%2 == 0)
+	}
+
+	c.drawFinderPattern(3, 3)
+	c.drawFinderPattern(c.Size-4, 3)
+	c.drawFinderPattern(3, c.Size-4)
+
+	positions := c.getAlignmentPatternPositions()
+	for i, x := range positions {
+		for j, y := range positions {
+			// The corners of the finder patterns are skipped.
+			isFinderCorner := (i == 0 && j == 0) ||
+				(i == 0 && j == len(positions)-1) ||
+				(i == len(positions)-1 && j == 0)
+			if !isFinderCorner {
+				c.drawAlignmentPattern(x, y)
+			}
+		}
+	}
+
+	// The format bits are reserved with a dummy, they're drawn along with the mask.
+	c.drawFormatBits(LevelL, 0)
+	c.drawVersionBits()
+}
+
+// drawFinderPattern draws the finder pattern centered at the module along with its separator.
+func (c *Code) drawFinderPattern(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || yy < 0 || xx >= c.Size || yy >= c.Size {
+				continue
+			}
+
+			dist := chebyshevDistance(dx, dy)
+			c.setFunctionModule(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+func (c *Code) drawAlignmentPattern(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			c.setFunctionModule(x+dx, y+dy, chebyshevDistance(dx, dy) != 1)
+		}
+	}
+}
+
+func (c *Code) getAlignmentPatternPositions() []int {
+	if c.Version == 1 {
+		return nil
+	}
+
+	count := c.Version/7 + 2
+
+	step := (c.Version*4 + count*2 + 1) / (count*2 - 2) * 2
+	if c.Version == 32 {
+		step = 26
+	}
+
+	positions := make([]int, count)
+	positions[0] = 6
+	for i, pos := count-1, c.Size-7; i >= 1; i, pos = i-1, pos-step {
+		positions[i] = pos
+	}
+
+	return positions
+}
+
+func (c *Code) drawFormatBits(level Level, mask int) {
+	data := levelFormatBits[level]<<3 | mask
+
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * formatGenerator)
+	}
+	bits := (data<<10 | rem) ^ formatMask
+
+	// The first copy goes around the top left finder pattern.
+	for i := 0; i <= 5; i++ {
+		c.setFunctionModule(8, i, getBit(bits, i))
+	}
+	c.setFunctionModule(8, 7, getBit(bits, 6))
+	c.setFunctionModule(8, 8, getBit(bits, 7))
+	c.setFunctionModule(7, 8, getBit(bits, 8))
+	for i := 9; i < 15; i++ {
+		c.setFunctionModule(14-i, 8, getBit(bits, i))
+	}
+
+	// The second copy is split between the other finder patterns.
+	for i := 0; i < 8; i++ {
+		c.setFunctionModule(c.Size-1-i, 8, getBit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+		c.setFunctionModule(8, c.Size-15+i, getBit(bits, i))
+	}
+
+	// It's the dark module, it's always dark.
+	c.setFunctionModule(8, c.Size-8, true)
+}
+
+func (c *Code) drawVersionBits() {
+	if c.Version < 7 {
+		return
+	}
+
+	rem := c.Version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * versionGenerator)
+	}
+	bits := c.Version<<12 | rem
+
+	for i := 0; i < 18; i++ {
+		var (
+			bit = getBit(bits, i)
+			a   = c.Size - 11 + i%3
+			b   = i / 3
+		)
+
+		c.setFunctionModule(a, b, bit)
+		c.setFunctionModule(b, a, bit)
+	}
+}
+
+// drawCodewords draws the codewords in the zigzag order going up and down the column pairs from the right.
+func (c *Code) drawCodewords(codewords []byte) {
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		// The vertical timing pattern is skipped.
+		if right == 6 {
+			right = 5
+		}
+
+		for vert := 0; vert < c.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+
+				y := vert
+				if (right+1)&2 == 0 {
+					y = c.Size - 1 - vert
+				}
+
+				if c.isFunction[y][x] || i >= len(codewords)*8 {
+					continue
+				}
+
+				c.modules[y][x] = getBit(int(codewords[i>>3]), 7-(i&7))
+				i++
+			}
+		}
+	}
+}
+
+func (c *Code) applyMask(mask int) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.isFunction[y][x] {
+				continue
+			}
+
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+
+			c.modules[y][x] = c.modules[y][x] != invert
+		}
+	}
+}
+
+// getPenalty scores the look of the code, the mask with the lowest score is the easiest to scan.
+func (c *Code) getPenalty() int {
+	var (
+		penalty int
+		dark    int
+	)
+
+	for i := 0; i < c.Size; i++ {
+		var (
+			row = make([]bool, c.Size)
+			col = make([]bool, c.Size)
+		)
+		for j := 0; j < c.Size; j++ {
+			row[j] = c.modules[i][j]
+			col[j] = c.modules[j][i]
+
+			if row[j] {
+				dark++
+			}
+		}
+
+		penalty += getLinePenalty(row) + getLinePenalty(col)
+	}
+
+	for y := 0; y < c.Size-1; y++ {
+		for x := 0; x < c.Size-1; x++ {
+			color := c.modules[y][x]
+			if color == c.modules[y][x+1] && color == c.modules[y+1][x] && color == c.modules[y+1][x+1] {
+				penalty += penaltyBlock
+			}
+		}
+	}
+
+	total := c.Size * c.Size
+	deviation := dark*100/total - 50
+	if deviation < 0 {
+		deviation = -deviation
+	}
+	penalty += deviation / 5 * penaltyBalance
+
+	return penalty
+}
+
+// getLinePenalty scores the runs of the same color and the patterns looking like the finder one.
+func getLinePenalty(line []bool) int {
+	var penalty int
+
+	runLen := 1
+	for i := 1; i <= len(line); i++ {
+		if i < len(line) && line[i] == line[i-1] {
+			runLen++
+			continue
+		}
+
+		if runLen >= 5 {
+			penalty += penaltyRun + runLen - 5
+		}
+		runLen = 1
+	}
+
+	for _, pattern := range finderLikePatterns {
+		for i := 0; i+len(pattern) <= len(line); i++ {
+			matched := true
+			for j, module := range pattern {
+				if line[i+j] != module {
+					matched = false
+					break
+				}
+			}
+
+			if matched {
+				penalty += penaltyFinder
+			}
+		}
+	}
+
+	return penalty
+}
+
+func chebyshevDistance(dx, dy int) int {
+	if dx < 0 {
+		dx = -dx
+	}
+	if dy < 0 {
+		dy = -dy
+	}
+	if dx > dy {
+		return dx
+	}
+
+	return dy
+}
+
+func getBit(x, i int) bool {
+	return (x>>uint(i))&1 != 0
+}
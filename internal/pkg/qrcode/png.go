@@ -0,0 +1,42 @@
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+const (
+	// quietZone is the light border around the code in modules, the standard asks for 4 at least.
+	quietZone = 4
+)
+
+// PNG renders the code to the grayscale PNG, every module is scale pixels wide.
+func (c *Code) PNG(scale int) ([]byte, error) {
+	if scale < 1 {
+		scale = 1
+	}
+
+	size := (c.Size + quietZone*2) * scale
+
+	img := image.NewGray(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			pixel := color.Gray{Y: 0xFF}
+			if c.Module(x/scale-quietZone, y/scale-quietZone) {
+				pixel = color.Gray{Y: 0x00}
+			}
+
+			img.SetGray(x, y, pixel)
+		}
+	}
+
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		return nil, fmt.Errorf("can't encode png: %w", err)
+	}
+
+	return b.Bytes(), nil
+}
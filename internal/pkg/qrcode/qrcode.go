@@ -0,0 +1,240 @@
+// Package qrcode encodes bytes into QR codes (ISO/IEC 18004) using the byte mode only.
+package qrcode
+
+import (
+	"errors"
+)
+
+type Level int
+
+const (
+	LevelL Level = iota // LevelL restores 7% of the codewords.
+	LevelM              // LevelM restores 15% of the codewords.
+	LevelQ              // LevelQ restores 25% of the codewords.
+	LevelH              // LevelH restores 30% of the codewords.
+)
+
+const (
+	minVersion = 1
+	maxVersion = 40
+
+	modeByte = 0x4
+
+	padByteEven = 0xEC
+	padByteOdd  = 0x11
+)
+
+var (
+	ErrTooLong = errors.New("data is too long")
+)
+
+var (
+	// levelFormatBits are the bits of the levels in the format info, they aren't in the level order.
+	levelFormatBits = [...]int{1, 0, 3, 2}
+
+	// eccCodewordsPerBlock and eccBlockCount are indexed by the level and the version, the 0 version is unused.
+	eccCodewordsPerBlock = [4][41]int{
+		{-1, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+		{-1, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28},
+		{-1, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+		{-1, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	}
+	eccBlockCount = [4][41]int{
+		{-1, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+		{-1, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},
+		{-1, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},
+		{-1, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81},
+	}
+)
+
+// Code is the module matrix of the QR code, true modules are dark.
+type Code struct {
+	Version int
+	Size    int
+
+	modules    [][]bool
+	isFunction [][]bool
+}
+
+// Encode encodes the data with the smallest version fitting it at the level.
+func Encode(data []byte, level Level) (*Code, error) {
+	version := minVersion
+	for ; version <= maxVersion; version++ {
+		if getDataBitLength(data, version) <= getDataCodewordCount(version, level)*8 {
+			break
+		}
+	}
+	if version > maxVersion {
+		return nil, ErrTooLong
+	}
+
+	codewords := addErrorCorrection(encodeDataCodewords(data, version, level), version, level)
+
+	c := newCode(version)
+	c.drawFunctionPatterns()
+	c.drawCodewords(codewords)
+
+	var (
+		bestMask    = -1
+		bestPenalty int
+	)
+	for mask := 0; mask < 8; mask++ {
+		c.applyMask(mask)
+		c.drawFormatBits(level, mask)
+
+		if penalty := c.getPenalty(); bestMask == -1 || penalty < bestPenalty {
+			bestMask, bestPenalty = mask, penalty
+		}
+
+		// The mask is a xor, so applying it again undoes it.
+		c.applyMask(mask)
+	}
+
+	c.applyMask(bestMask)
+	c.drawFormatBits(level, bestMask)
+
+	return c, nil
+}
+
+// Module reports whether the module at the column x and the row y is dark.
+func (c *Code) Module(x, y int) bool {
+	if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+		return false
+	}
+
+	return c.modules[y][x]
+}
+
+func newCode(version int) *Code {
+	size := version*4 + 17
+
+	c := &Code{
+		Version:    version,
+		Size:       size,
+		modules:    make([][]bool, size),
+		isFunction: make([][]bool, size),
+	}
+	for y := 0; y < size; y++ {
+		c.modules[y] = make([]bool, size)
+		c.isFunction[y] = make([]bool, size)
+	}
+
+	return c
+}
+
+func getDataBitLength(data []byte, version int) int {
+	return 4 + getCharCountBitLength(version) + len(data)*8
+}
+
+func getCharCountBitLength(version int) int {
+	if version <= 9 {
+		return 8
+	}
+
+	return 16
+}
+
+// getRawDataModuleCount returns the number of modules left for the data and the error correction.
+func getRawDataModuleCount(version int) int {
+	count := (16*version+128)*version + 64
+	if version >= 2 {
+		alignmentCount := version/7 + 2
+		count -= (25*alignmentCount-10)*alignmentCount - 55
+		if version >= 7 {
+			count -= 36
+		}
+	}
+
+	return count
+}
+
+func getDataCodewordCount(version int, level Level) int {
+	return getRawDataModuleCount(version)/8 - eccCodewordsPerBlock[level][version]*eccBlockCount[level][version]
+}
+
+func encodeDataCodewords(data []byte, version int, level Level) []byte {
+	var bb bitBuffer
+	bb.Append(modeByte, 4)
+	bb.Append(len(data), getCharCountBitLength(version))
+	for _, b := range data {
+		bb.Append(int(b), 8)
+	}
+
+	capacity := getDataCodewordCount(version, level) * 8
+
+	terminator := capacity - len(bb)
+	if terminator > 4 {
+		terminator = 4
+	}
+	bb.Append(0, terminator)
+	bb.Append(0, (8-len(bb)%8)%8)
+
+	for padByte := padByteEven; len(bb) < capacity; padByte ^= padByteEven ^ padByteOdd {
+		bb.Append(padByte, 8)
+	}
+
+	return bb.Bytes()
+}
+
+// addErrorCorrection splits the data into the blocks, adds the error correction to every block
+// and interleaves the blocks.
+func addErrorCorrection(data []byte, version int, level Level) []byte {
+	var (
+		blockCount      = eccBlockCount[level][version]
+		blockEccLength  = eccCodewordsPerBlock[level][version]
+		rawCodewords    = getRawDataModuleCount(version) / 8
+		shortBlockCount = blockCount - rawCodewords%blockCount
+		shortBlockLen   = rawCodewords / blockCount
+		divisor         = computeReedSolomonDivisor(blockEccLength)
+	)
+
+	blocks := make([][]byte, 0, blockCount)
+	for i, k := 0, 0; i < blockCount; i++ {
+		dataLen := shortBlockLen - blockEccLength
+		if i >= shortBlockCount {
+			dataLen++
+		}
+
+		block := append([]byte(nil), data[k:k+dataLen]...)
+		k += dataLen
+
+		ecc := computeReedSolomonRemainder(block, divisor)
+
+		// The short blocks get a placeholder, so all the blocks are of the same length.
+		if i < shortBlockCount {
+			block = append(block, 0)
+		}
+
+		blocks = append(blocks, append(block, ecc...))
+	}
+
+	result := make([]byte, 0, rawCodewords)
+	for i := range blocks[0] {
+		for j, block := range blocks {
+			if i != shortBlockLen-blockEccLength || j >= shortBlockCount {
+				result = append(result, block[i])
+			}
+		}
+	}
+
+	return result
+}
+
+type bitBuffer []bool
+
+func (bb *bitBuffer) Append(value, length int) {
+	for i := length - 1; i >= 0; i-- {
+		*bb = append(*bb, (value>>uint(i))&1 != 0)
+	}
+}
+
+func (bb bitBuffer) Bytes() []byte {
+	result := make([]byte, len(bb)/8)
+	for i, bit := range bb {
+		if bit {
+			result[i/8] |= 1 << uint(7-i%8)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,199 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		level   Level
+		version int
+		want    []string // want rows are dark for # and light for ., they are checked against another encoder.
+	}{
+		{
+			name:    "version 1",
+			data:    "HELLO WORLD",
+			level:   LevelM,
+			version: 1,
+			want: []string{
+				"#######.##..#.#######",
+				"#.....#....#..#.....#",
+				"#.###.#..#.#..#.###.#",
+				"#.###.#.#..#..#.###.#",
+				"#.###.#.###.#.#.###.#",
+				"#.....#.#..#..#.....#",
+				"#######.#.#.#.#######",
+				"........#..##........",
+				"#...#.######.#####..#",
+				"...#....#.###....####",
+				"..######..##.##.#..#.",
+				"#####...##...#.......",
+				"#####.#.#.#.#.##..##.",
+				"........#.#.####.#.##",
+				"#######.###.#.#.##.#.",
+				"#.....#..#.###.##..##",
+				"#.###.#.##.#.##...##.",
+				"#.###.#..#..#...##.##",
+				"#.###.#..###...###...",
+				"#.....#....#.#.......",
+				"#######.#########.#.#",
+			},
+		},
+		{
+			// The version 7 has the version info and the blocks of the different lengths at the level.
+			name: "version 7",
+			data: strings.Join([]string{
+				"[Interface]",
+				"PrivateKey = cGVlcjAxcHJpdmF0ZWtleQ==",
+				"Address = 10.8.0.4/32",
+				"DNS = 1.1.1.1",
+			}, "\n"),
+			level:   LevelQ,
+			version: 7,
+			want: []string{
+				"#######........#####..##.######.....#.#######",
+				"#.....#..#####.#..#####.#......###.#..#.....#",
+				"#.###.#.###.###..#..#.#......#..##.#..#.###.#",
+				"#.###.#..#..###.#.#.####.#..##...#.##.#.###.#",
+				"#.###.#.##..####.#..########..#.#####.#.###.#",
+				"#.....#.##....#.....#...####.#.###....#.....#",
+				"#######.#.#.#.#.#.#.#.#.#.#.#.#.#.#.#.#######",
+				".........#.....##...#...#....#..#...#........",
+				".#..#.#.####..#.#..########...###.#..#.##.#..",
+				"###.#..##..###..##...#.##.#.#####..##.#.####.",
+				"#.######..#..##..#########..###....#.##...##.",
+				"###..#..#.#..####..#..#....##...#####..#....#",
+				"......####...###.#######...#..#.#...#.#..#..#",
+				"##.###.##.#.####.....##.#.#####.#..##.###.##.",
+				".####.##..##...######..#.######......#####.#.",
+				".#.###.#..##..#.##....##....#.#####...#..#..#",
+				"..#..########..#...###.#.#.#....#.##.#...#.##",
+				"###..#.#..####.###.###.####.#.##.#.##.#..##.#",
+				"###.###..#....#.#.###.#.#..#..#.##.....###.#.",
+				"#..##.....#.#..###.#....###...#.##...#.#...#.",
+				"#...######.###.....#######.....####.#####.#.#",
+				"#####...###..##.#.###...#...#.#.##.##...#..##",
+				"#.###.#.###..##...#.#.#.#.....#.##.##.#.#.##.",
+				"#...#...##.##..#..###...###.....#####...#...#",
+				".#.######.....##..########...##.#########.##.",
+				".#........#.#....#.#.##..#.####.##....##...#.",
+				"..#..##.....##.#..#..#....#......##..#.##....",
+				"#.#.##.###.####.#..#...#.###..###....#.....##",
+				"......#.#.###..#.####.###..#.#####..###.##.##",
+				"#.#.#....#..##.#####..##.#..#.##....#.##.#..#",
+				".###..##.#.#..####..#.####.##########....#.#.",
+				".##.#..#..#.###....#.##.#####..###...####...#",
+				".#.####.#.#...#.#.##...#...#..###....##.#..#.",
+				"..#....#......####.#.###.#.#.###....####.##.#",
+				"....#.#....##..##..#..##.....#####.##..#.#.#.",
+				".####..#...##.####.#...##.##.....##.###.#..##",
+				"#..##.#.###..#..##.#########..#.##..#####...#",
+				"........####.#.#..###...##...###...##...##...",
+				"#######.....#.#.##..#.#.##.##.#.##.##.#.#..#.",
+				"#.....#..##.....##.##...#####.....###...##...",
+				"#.###.#.#....#.....########...#.#.#.#####..##",
+				"#.###.#..####..##.##.###.###..####....#.##..#",
+				"#.###.#...#.####..##.####....##.##......####.",
+				"#.....#.##...#.##..#.#....##.#.##.##.#..#....",
+				"#######..##.########.#..#.#..##.##.###..###.#",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Encode([]byte(tt.data), tt.level)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+
+			if c.Version != tt.version {
+				t.Fatalf("Encode() version = %d, want %d", c.Version, tt.version)
+			}
+
+			if c.Size != len(tt.want) {
+				t.Fatalf("Encode() size = %d, want %d", c.Size, len(tt.want))
+			}
+
+			for y, row := range tt.want {
+				var b strings.Builder
+				for x := 0; x < c.Size; x++ {
+					if c.Module(x, y) {
+						b.WriteByte('#')
+					} else {
+						b.WriteByte('.')
+					}
+				}
+
+				if got := b.String(); got != row {
+					t.Errorf("row %d = %s, want %s", y, got, row)
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeTooLong(t *testing.T) {
+	if _, err := Encode(make([]byte, 2954), LevelL); !errors.Is(err, ErrTooLong) {
+		t.Errorf("Encode() error = %v, want %v", err, ErrTooLong)
+	}
+}
+
+func TestPNG(t *testing.T) {
+	c, err := Encode([]byte("HELLO WORLD"), LevelM)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		scale int
+		size  int
+	}{
+		{name: "scaled", scale: 8, size: (21 + quietZone*2) * 8},
+		{name: "unscaled", scale: 0, size: 21 + quietZone*2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := c.PNG(tt.scale)
+			if err != nil {
+				t.Fatalf("PNG() error = %v", err)
+			}
+
+			img, err := png.Decode(bytes.NewReader(raw))
+			if err != nil {
+				t.Fatalf("can't decode png: %v", err)
+			}
+
+			if bounds := img.Bounds(); bounds.Dx() != tt.size || bounds.Dy() != tt.size {
+				t.Fatalf("PNG() bounds = %v, want %dx%d", bounds, tt.size, tt.size)
+			}
+
+			scale := tt.size / (c.Size + quietZone*2)
+
+			// The quiet zone is light and the corner of the finder pattern next to it is dark.
+			for _, p := range []struct {
+				x, y int
+				dark bool
+			}{
+				{x: 0, y: 0, dark: false},
+				{x: quietZone*scale - 1, y: quietZone*scale - 1, dark: false},
+				{x: quietZone * scale, y: quietZone * scale, dark: true},
+				{x: (quietZone+1)*scale - 1, y: (quietZone+1)*scale - 1, dark: true},
+				{x: tt.size - 1, y: tt.size - 1, dark: false},
+			} {
+				r, _, _, _ := img.At(p.x, p.y).RGBA()
+				if dark := r == 0; dark != p.dark {
+					t.Errorf("pixel at %d,%d dark = %t, want %t", p.x, p.y, dark, p.dark)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package qrcode
+
+const (
+	// gfPolynomial is the reducing polynomial of GF(2^8) used by QR codes.
+	gfPolynomial = 0x11D
+)
+
+// computeReedSolomonDivisor returns the coefficients of the generator polynomial of the degree,
+// the leading coefficient is omitted since it's always 1.
+func computeReedSolomonDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := 0; j < degree; j++ {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < degree {
+				result[j] ^= result[j+1]
+			}
+		}
+
+		root = gfMultiply(root, 0x02)
+	}
+
+	return result
+}
+
+func computeReedSolomonRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+
+		for i, coef := range divisor {
+			result[i] ^= gfMultiply(coef, factor)
+		}
+	}
+
+	return result
+}
+
+func gfMultiply(x, y byte) byte {
+	var z int
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * gfPolynomial)
+		z ^= int((y>>uint(i))&1) * int(x)
+	}
+
+	return byte(z)
+}